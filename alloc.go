@@ -0,0 +1,22 @@
+package argon2
+
+// An allocator provides the memory for the matrix of a workspace,
+// along with its other buffers derived from the password.
+type allocator interface {
+	// alloc returns n zeroed blocks.
+	alloc(n int) ([][128]uint64, error)
+
+	// free releases the blocks returned by alloc.
+	// They must not be used afterwards.
+	free(b [][128]uint64)
+}
+
+// heapAllocator allocates the matrix on the Go heap.
+// It is the default.
+type heapAllocator struct{}
+
+func (heapAllocator) alloc(n int) ([][128]uint64, error) {
+	return make([][128]uint64, n), nil
+}
+
+func (heapAllocator) free(b [][128]uint64) {}
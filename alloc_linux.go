@@ -0,0 +1,129 @@
+//go:build linux && go1.17
+// +build linux,go1.17
+
+package argon2
+
+import (
+	"os"
+	"runtime"
+	"syscall"
+	"unsafe"
+)
+
+// offHeapAllocator is used when Params.OffHeap is set.
+var offHeapAllocator allocator = mmapAllocator{}
+
+// secureAllocator is used when Params.SecureMemory is set.
+var secureAllocator allocator = mlockAllocator{}
+
+// Missing from the syscall package on some architectures
+const madvDontdump = 0x10 // MADV_DONTDUMP
+
+// mmapAllocator places the matrix in an anonymous memory mapping,
+// outside the Go heap, so that it does not count towards the heap goal
+// and is returned to the operating system as soon as it is freed.
+// The mapping is backed by transparent huge pages where available,
+// which reduces TLB misses when accessing a large matrix.
+type mmapAllocator struct{}
+
+func (mmapAllocator) alloc(n int) ([][128]uint64, error) {
+	mem, err := mmap(n)
+	if err != nil || mem == nil {
+		return nil, err
+	}
+	// Huge pages are only a hint, and may be disabled
+	syscall.Madvise(mem, syscall.MADV_HUGEPAGE)
+	return blocksOf(mem), nil
+}
+
+func (mmapAllocator) free(b [][128]uint64) {
+	munmap(b)
+}
+
+// mlockAllocator is like mmapAllocator, but locks the mapping into RAM,
+// so that it is never written to swap, and excludes it from core dumps.
+// Huge pages are not requested, since a locked huge page
+// cannot be split up or moved by the kernel.
+type mlockAllocator struct{}
+
+func (mlockAllocator) alloc(n int) ([][128]uint64, error) {
+	mem, err := mmap(n)
+	if err != nil || mem == nil {
+		return nil, err
+	}
+	if err := syscall.Mlock(mem); err != nil {
+		syscall.Munmap(mem)
+		return nil, &MemoryLockError{Size: int64(len(mem)), Limit: memlockLimit(), Err: os.NewSyscallError("mlock", err)}
+	}
+	if err := syscall.Madvise(mem, madvDontdump); err != nil {
+		syscall.Munlock(mem)
+		syscall.Munmap(mem)
+		return nil, &MemoryLockError{Size: int64(len(mem)), Limit: -1, Err: os.NewSyscallError("madvise", err)}
+	}
+	return blocksOf(mem), nil
+}
+
+func (mlockAllocator) free(b [][128]uint64) {
+	if len(b) == 0 {
+		return
+	}
+	// The blocks have already been wiped
+	syscall.Munlock(blockBytes(b))
+	munmap(b)
+}
+
+// mmap maps n blocks of anonymous memory,
+// which the kernel fills with zeros.
+func mmap(n int) ([]byte, error) {
+	if n == 0 {
+		return nil, nil
+	}
+	size := uint64(n) * 1024
+	if uint64(int(size)) != size {
+		return nil, os.NewSyscallError("mmap", syscall.ENOMEM)
+	}
+	mem, err := syscall.Mmap(-1, 0, int(size), syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_PRIVATE|syscall.MAP_ANON)
+	if err != nil {
+		return nil, os.NewSyscallError("mmap", err)
+	}
+	return mem, nil
+}
+
+// munmap unmaps blocks mapped by mmap.
+func munmap(b [][128]uint64) {
+	if len(b) == 0 {
+		return
+	}
+	if err := syscall.Munmap(blockBytes(b)); err != nil {
+		panic("argon2: munmap: " + err.Error())
+	}
+}
+
+// blocksOf returns the blocks in mem.
+func blocksOf(mem []byte) [][128]uint64 {
+	return unsafe.Slice((*[128]uint64)(unsafe.Pointer(&mem[0])), len(mem)/1024)
+}
+
+// blockBytes returns the memory underlying the blocks in b.
+func blockBytes(b [][128]uint64) []byte {
+	return unsafe.Slice((*byte)(unsafe.Pointer(&b[0])), len(b)*1024)
+}
+
+// memlockLimit returns the soft limit on locked memory, in bytes,
+// or -1 if there is no limit or it cannot be determined.
+func memlockLimit() int64 {
+	var lim syscall.Rlimit
+	if err := syscall.Getrlimit(rlimitMemlock(), &lim); err != nil || lim.Cur > 1<<62 {
+		return -1
+	}
+	return int64(lim.Cur)
+}
+
+// rlimitMemlock returns RLIMIT_MEMLOCK, which the syscall package lacks.
+func rlimitMemlock() int {
+	switch runtime.GOARCH {
+	case "mips", "mipsle", "mips64", "mips64le":
+		return 9
+	}
+	return 8
+}
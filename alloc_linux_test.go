@@ -0,0 +1,84 @@
+//go:build linux && go1.17
+// +build linux,go1.17
+
+package argon2
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"syscall"
+	"testing"
+	"unsafe"
+)
+
+// vmFlags returns the VmFlags of the mapping containing b, from /proc/self/smaps.
+func vmFlags(t *testing.T, b [][128]uint64) []string {
+	t.Helper()
+	smaps, err := ioutil.ReadFile("/proc/self/smaps")
+	if err != nil {
+		t.Skip(err)
+	}
+	addr := uintptr(unsafe.Pointer(&b[0]))
+	inMapping := false
+	for _, line := range strings.Split(string(smaps), "\n") {
+		var start, end uintptr
+		if n, _ := fmt.Sscanf(line, "%x-%x", &start, &end); n == 2 {
+			inMapping = start <= addr && addr < end
+		} else if inMapping && strings.HasPrefix(line, "VmFlags:") {
+			return strings.Fields(line[len("VmFlags:"):])
+		}
+	}
+	t.Fatalf("mapping at %#x not found", addr)
+	return nil
+}
+
+func hasFlag(flags []string, flag string) bool {
+	for _, f := range flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSecureAllocator(t *testing.T) {
+	b, err := secureAllocator.alloc(16)
+	if errors.Is(err, ErrMemoryLock) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer secureAllocator.free(b)
+	flags := vmFlags(t, b)
+	// lo: locked, dd: excluded from core dumps
+	if !hasFlag(flags, "lo") || !hasFlag(flags, "dd") {
+		t.Errorf("VmFlags = %v, want lo and dd", flags)
+	}
+}
+
+// Checks that the error names the limit when it is too small
+func TestSecureAllocatorLimit(t *testing.T) {
+	var old syscall.Rlimit
+	if err := syscall.Getrlimit(rlimitMemlock(), &old); err != nil {
+		t.Skip(err)
+	}
+	lim := old
+	lim.Cur = 64 << 10
+	if err := syscall.Setrlimit(rlimitMemlock(), &lim); err != nil {
+		t.Skip(err)
+	}
+	defer syscall.Setrlimit(rlimitMemlock(), &old)
+
+	b, err := secureAllocator.alloc(1024)
+	if err == nil {
+		secureAllocator.free(b)
+		t.Skip("memory locking is not limited for this process")
+	}
+	var e *MemoryLockError
+	if !errors.As(err, &e) || e.Limit != 64<<10 || e.Size != 1024*1024 {
+		t.Errorf("got %#v, want a MemoryLockError with the size and limit", err)
+	}
+}
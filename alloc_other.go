@@ -0,0 +1,22 @@
+//go:build !linux || !go1.17
+// +build !linux !go1.17
+
+package argon2
+
+import "errors"
+
+// offHeapAllocator is used when Params.OffHeap is set.
+// The matrix is only placed outside the heap on Linux.
+var offHeapAllocator allocator = heapAllocator{}
+
+// secureAllocator is used when Params.SecureMemory is set.
+// Memory can only be locked on Linux.
+var secureAllocator allocator = unsupportedAllocator{}
+
+type unsupportedAllocator struct{}
+
+func (unsupportedAllocator) alloc(n int) ([][128]uint64, error) {
+	return nil, &MemoryLockError{Size: int64(n) * 1024, Limit: -1, Err: errors.New("not supported on this platform")}
+}
+
+func (unsupportedAllocator) free(b [][128]uint64) {}
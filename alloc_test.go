@@ -0,0 +1,90 @@
+package argon2
+
+import (
+	"bytes"
+	"errors"
+	"runtime"
+	"testing"
+)
+
+func TestAllocator(t *testing.T) {
+	for _, alloc := range []allocator{heapAllocator{}, offHeapAllocator} {
+		b, err := alloc.alloc(1000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(b) != 1000 {
+			t.Fatalf("%T: got %d blocks, want 1000", alloc, len(b))
+		}
+		for i := range b {
+			if b[i] != [128]uint64{} {
+				t.Fatalf("%T: block %d is not zero", alloc, i)
+			}
+			b[i][0], b[i][127] = uint64(i), uint64(i)
+		}
+		alloc.free(b)
+	}
+}
+
+// Checks that the memory options do not affect the derived key
+func TestMemoryOptions(t *testing.T) {
+	pw := repeat(0x0, 16)
+	salt := repeat(0x1, 8)
+	base := Params{Variant: Argon2id, Time: 2, Memory: 256, Lanes: 4, TagLength: 32}
+	want, err := base.Key(pw, salt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		name string
+		set  func(p *Params)
+	}{
+		{"OffHeap", func(p *Params) { p.OffHeap = true }},
+		{"SecureMemory", func(p *Params) { p.SecureMemory = true }},
+	} {
+		p := base
+		tt.set(&p)
+		got, err := p.Key(pw, salt)
+		if errors.Is(err, ErrMemoryLock) {
+			t.Logf("%s: %v", tt.name, err)
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s: got %x, want %x", tt.name, got, want)
+		}
+
+		h, err := NewHasher(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out := make([]byte, 32)
+		if err := h.Key(out, pw, salt); err != nil || !bytes.Equal(out, want) {
+			t.Errorf("Hasher with %s = %x, %v, want %x", tt.name, out, err, want)
+		}
+	}
+}
+
+// Checks that an off-heap matrix does not count towards the heap
+func TestOffHeapMemStats(t *testing.T) {
+	if offHeapAllocator == (heapAllocator{}) {
+		t.Skip("no off-heap allocator on this platform")
+	}
+	const m = 64 << 10 // 64 MiB
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	b, err := offHeapAllocator.alloc(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range b {
+		b[i][0] = 1
+	}
+	runtime.ReadMemStats(&after)
+	offHeapAllocator.free(b)
+	if grew := int64(after.HeapAlloc) - int64(before.HeapAlloc); grew > m*1024/2 {
+		t.Errorf("heap grew by %d bytes", grew)
+	}
+}
@@ -2,13 +2,21 @@
 // designed by Alex Biryukov, Daniel Dinu, and Dmitry Khovratovich,
 // as specified in the document
 //
-//     https://github.com/P-H-C/phc-winner-argon2/raw/54617af02de0055b90e39c4204058bb9a84c2b78/argon2-specs.pdf
+//	https://github.com/P-H-C/phc-winner-argon2/raw/54617af02de0055b90e39c4204058bb9a84c2b78/argon2-specs.pdf
+//
+// The older version 1.0 is also supported, for verifying legacy hashes.
+//
+// Keys derived by earlier versions of this package with more than one lane
+// and more than 8 KiB of memory per lane do not match; see Key.
 //
 // Warning: This package is currently unstable; Argon2 has not yet been
 // finalized and is still undergoing design tweaks.
 package argon2
 
-import "errors"
+import (
+	"context"
+	"strconv"
+)
 
 const (
 	maxPar = 255
@@ -21,47 +29,162 @@ const (
 	minSalt     = 8
 	maxSalt     = 1<<32 - 1
 	maxPassword = 1<<32 - 1
+	maxSecret   = 1<<32 - 1
+	maxData     = 1<<32 - 1
+
+	minTag = 4
+	maxTag = 1<<32 - 1
+)
+
+// Supported versions of the Argon2 algorithm.
+const (
+	// Version10 is version 1.0 of Argon2.
+	// It is supported only for compatibility with existing hashes.
+	Version10 = 0x10
+
+	// Version13 is version 1.3 of Argon2,
+	// which is the version standardized in RFC 9106.
+	Version13 = 0x13
+)
+
+// A Variant selects one of the three flavors of Argon2.
+type Variant uint32
+
+const (
+	// Argon2d uses data-dependent memory access,
+	// which makes it the most resistant to GPU cracking attacks
+	// but vulnerable to side-channel timing attacks.
+	Argon2d Variant = 0
+
+	// Argon2i uses data-independent memory access,
+	// which is preferred for situations where side channels are a concern.
+	Argon2i Variant = 1
+
+	// Argon2id uses data-independent memory access for the first half
+	// of the first pass and data-dependent access for the rest.
+	// It is the variant recommended for password hashing by RFC 9106.
+	Argon2id Variant = 2
 )
 
-// Key derives a key from the password, salt, and cost parameters.
+func (v Variant) String() string {
+	switch v {
+	case Argon2d:
+		return "argon2d"
+	case Argon2i:
+		return "argon2i"
+	case Argon2id:
+		return "argon2id"
+	}
+	return "argon2(" + strconv.FormatUint(uint64(v), 10) + ")"
+}
+
+// Key derives a key from the password, salt, and cost parameters
+// using Argon2d. It is equivalent to Argon2d.Key.
 //
-// The salt must be at least 8 bytes long.
+// The salt must be at least 8 bytes long,
+// and keyLen must be at least 4.
 //
 // Mem is the amount of memory to use in kibibytes.
 // Mem must be at least 8*par, and will be rounded to a multiple of 4*par.
+//
+// Par is the number of lanes. Lanes are filled concurrently,
+// using at most GOMAXPROCS goroutines.
+//
+// The memory used by the computation is wiped before Key returns.
+//
+// For more control over the parameters, see Params.
+//
+// Earlier versions of this package let the first slice of the first pass
+// reference blocks in other lanes, contrary to the specification.
+// Keys derived by them with par > 1 and mem > 8*par do not match
+// the keys derived now, which agree with the reference implementation.
 func Key(password, salt []byte, n, par int, mem int64, keyLen int) ([]byte, error) {
-	if int64(len(password)) > maxPassword {
-		return nil, errors.New("argon: password too long")
-	}
+	return Argon2d.Key(password, salt, n, par, mem, keyLen)
+}
 
-	if len(salt) < minSalt {
-		return nil, errors.New("argon: salt too short")
-	} else if int64(len(salt)) > maxSalt {
-		return nil, errors.New("argon: salt too long")
-	}
+// Key derives a key from the password, salt, and cost parameters
+// using the variant v.
+//
+// The parameters have the same meaning and restrictions as for
+// the package-level Key function.
+func (v Variant) Key(password, salt []byte, n, par int, mem int64, keyLen int) ([]byte, error) {
+	return v.KeyWithSecret(password, salt, nil, nil, n, par, mem, keyLen)
+}
+
+// KeyWithSecret is like Key but additionally mixes a secret key
+// and associated data into the derived key.
+//
+// The secret is typically a server-side value (a "pepper")
+// that is stored separately from the hashes.
+// The data can be used to bind the key to a context,
+// such as a user ID.
+// Either may be nil.
+func (v Variant) KeyWithSecret(password, salt, secret, data []byte, n, par int, mem int64, keyLen int) ([]byte, error) {
+	return v.key(context.Background(), password, salt, secret, data, n, par, mem, keyLen)
+}
+
+// KeyContext is like Key but stops early if ctx is canceled.
+// It is equivalent to Argon2d.KeyContext.
+func KeyContext(ctx context.Context, password, salt []byte, n, par int, mem int64, keyLen int) ([]byte, error) {
+	return Argon2d.KeyContext(ctx, password, salt, n, par, mem, keyLen)
+}
+
+// KeyContext is like Key but stops early if ctx is canceled.
+//
+// Cancellation is checked between segments, of which there are
+// 4*par per pass. If ctx is canceled, the memory used so far
+// is wiped and ctx.Err() is returned.
+func (v Variant) KeyContext(ctx context.Context, password, salt []byte, n, par int, mem int64, keyLen int) ([]byte, error) {
+	return v.key(ctx, password, salt, nil, nil, n, par, mem, keyLen)
+}
 
+func (v Variant) key(ctx context.Context, password, salt, secret, data []byte, n, par int, mem int64, keyLen int) ([]byte, error) {
+	// Check for values that don't fit in Params
 	if n < 1 || int64(n) > maxIter {
-		return nil, errors.New("argon: invalid n")
+		return nil, ErrInvalidTime
 	}
-
 	if par < 1 || par > maxPar {
-		return nil, errors.New("argon: invalid par")
+		return nil, ErrInvalidLanes
 	}
-
 	if mem < minMemory || mem > maxMemory {
-		return nil, errors.New("argon: invalid mem")
+		return nil, ErrInvalidMemory
 	}
-
-	// Round down to a multiple of 4 * par
-	mem = mem / (4 * int64(par)) * (4 * int64(par))
-
-	if mem < 8*int64(par) {
-		mem = 8 * int64(par)
+	if keyLen < minTag || int64(keyLen) > maxTag {
+		return nil, ErrInvalidTagLength
 	}
 
-	// TODO: test keyLen
+	p := Params{
+		Variant:   v,
+		Version:   Version13,
+		Time:      uint32(n),
+		Memory:    uint32(mem),
+		Lanes:     uint32(par),
+		TagLength: uint32(keyLen),
+	}
+	return p.KeyContext(ctx, password, salt, secret, data)
+}
 
-	output := make([]byte, keyLen)
-	argon2(output, password, salt, nil, nil, uint32(par), uint32(mem), uint32(n), nil)
-	return output, nil
+// LongHash computes the variable-length hash function H' of Argon2
+// over the concatenation of the inputs and fills out with the result.
+//
+// H' is BLAKE2b of the output length followed by the input.
+// Outputs longer than 64 bytes are produced 32 bytes at a time
+// from a chain of BLAKE2b-512 hashes, as described in
+// section 3.3 of the Argon2 specification and section 3.3 of RFC 9106.
+//
+// The length of out must be between 1 and 2^32-1 bytes;
+// otherwise LongHash returns ErrInvalidTagLength.
+func LongHash(out []byte, in ...[]byte) error {
+	if len(out) < 1 || int64(len(out)) > maxTag {
+		return ErrInvalidTagLength
+	}
+	lh := longHash{st: new(hashState)}
+	lh.st.init()
+	lh.Init(len(out))
+	for _, b := range in {
+		lh.Write(b)
+	}
+	lh.Hash(out)
+	lh.wipe()
+	return nil
 }
@@ -1,9 +1,12 @@
 package argon2
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 )
 
 var zeros [16]byte
@@ -28,6 +31,31 @@ func ExampleKey() {
 	// Output: c5dd631f4e715853e0354326c56f7c3aac983e5d86f7fb02f935899c38690f9e
 }
 
+func TestKeyWithSecret(t *testing.T) {
+	// Test vector from the official repository
+	pw := repeat(0x1, 32)
+	salt := repeat(0x2, 16)
+	secret := repeat(0x3, 8)
+	data := repeat(0x4, 12)
+	want := "512b391b6f1162975371d30919734294f868e3be3984f3c1a13a4db9fabe4acb"
+
+	key, err := Argon2d.KeyWithSecret(pw, salt, secret, data, 3, 4, 32, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprintf("%x", key); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	key, err = Argon2d.KeyWithSecret(pw, salt, nil, nil, 3, 4, 32, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprintf("%x", key); got == want {
+		t.Errorf("secret and data had no effect on the key")
+	}
+}
+
 func TestKeyErr(t *testing.T) {
 	pw := zeros[:]
 	salt := ones[:]
@@ -47,4 +75,125 @@ func TestKeyErr(t *testing.T) {
 	} else if !strings.Contains(err.Error(), want) {
 		t.Errorf("got %q, expected %q", err, want)
 	}
+
+	want = "invalid variant"
+	_, err = Variant(3).Key(pw, salt, 3, 1, 8, 8)
+	if err == nil {
+		t.Errorf("got nil error, expected %q", want)
+	} else if !strings.Contains(err.Error(), want) {
+		t.Errorf("got %q, expected %q", err, want)
+	}
+}
+
+func TestKeyContext(t *testing.T) {
+	pw := zeros[:]
+	salt := ones[:]
+
+	want, err := Argon2id.Key(pw, salt, 3, 2, 64, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := Argon2id.KeyContext(context.Background(), pw, salt, 3, 2, 64, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprintf("%x", key) != fmt.Sprintf("%x", want) {
+		t.Errorf("got %x, want %x", key, want)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	key, err = KeyContext(ctx, pw, salt, 3, 2, 64, 32)
+	if err != context.Canceled || key != nil {
+		t.Errorf("got %x, %v; want nil, %v", key, err, context.Canceled)
+	}
+
+	// Should take much longer than the timeout
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	key, err = KeyContext(ctx, pw, salt, 1000, 4, 1<<14, 32)
+	if err != context.DeadlineExceeded || key != nil {
+		t.Errorf("got %x, %v; want nil, %v", key, err, context.DeadlineExceeded)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("took %v to notice cancellation", d)
+	}
+}
+
+func TestLongHash(t *testing.T) {
+	// Computed with an independent implementation of H'
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{1, "e3"},
+		{4, "785eed78"},
+		{32, "668616ce62e3871d75e94cb8e51e02bec2728826fc41e8a73329d42f8f36a64f"},
+		{63, "c36bf4940028e98db5ced7a8219d83c1967f5074728c2335129457c48636478300389462da3e34a6dacb192328d7a85cff0bda620ff9ef2369a916daa0a39e"},
+		{64, "83282bdd25d07b4c815c8676d56c2978ba5b6dfba96ec67c154829213ec50c8f6581d44a1af4100a5a1ab767fe58f05bc434b4ffc9deefc5a36b958d10475429"},
+		{65, "339ae0af9609bb08b50c63edda48f5feb1010af624465efa5a92a320d0e8c1f15c50030612022b67cd0d7142d10e66e595f6d983efe918ebf38d0fa1408c5a0051"},
+		{96, "f627be3b476965fe15c679956f9f8c9953e18482528b6582b4e3715e85b5557006a2e645f9dff4e3a95f7a7591d6068ab52546a582f7a83a1e2c8d050a38e5b9d9a6960142c0b7764becf16189407eca94a0e418df5f825095fa802f23202ff5"},
+		{97, "7a3c441c9ea37adb211ee47ece3a2228e75113ca41979a568aa17946765c5f1ad0c8ee9888f4f96a82b599b6673037ea35cc91b68181ffb960d5cd00f533a531fb8c6e2a7b852cc0faa5b6559511c1d23f96dbaf52e3a16d4fbfec76c0844da936"},
+		{127, "3a570d55b7beb5a31a23b8789d675524eb06c816742db08f4fd09589edbf0cfc5dbbf09fe4f4d67afac9be6e2bcda1fe2f40bdfd38ef64b829996c66d309cea81f0a4edfee000945eadfd836dcbbb58556621e7e28f55d7548d48f1ca6307801018d347780b9c933f23300a2cafa0f6136466ae0fe2d5602b3ede5e04ff53f"},
+		{128, "7f24ec81aaa6f700666d36c26bfde94ec7473a451ed9a1e41fa25d3cbdd400f5d1cf89329259638fd51edc73f24d2028c13f12e4f4d8b27c38d95646e1f8237235cf5f3044a5edf3815e7737349636f22966ebfae085335df0f82dae7dc4896d47a6fef85ce64ccf51db2327fdfd838fc1478c2ef89921acd4194670446811ae"},
+		{129, "3e0a40ec8c767274f001a5b3fcd28fc02653d19ec21adfadf3eeac86b05729e8ac7b06ac4bae69a1cdd3810c639ba9cb764a6f2c2acbd8c3f779020ab74d103227771e71d9a3f8b89702f0b9176e6db32345657bbc6e62a0eeda698079822eb4a879066208de01127fc70052932176d665b7b7f21093ccd0ee7c8d9827feaea2bc"},
+		{160, "638c8d17303c0d77feb8fbda559e552f96098b6bb8318a81e71a87bc9cd8a8e639a2f474f215c61701c879a18d090cc56c473748ee8d0899a61e5b2d05f4a9ea5df3a1a951009d277492dd7144c3908722442f4894f98faad058ce18127a8828a6242d3b3a8b4cbf8c35d750b25f4c21622f5ab878a3cb7adb200ff299c63d0bd382618a86c461777e225246c4cd58b979b475ed25f3395e91626ce409e98ee7"},
+	}
+	for _, tt := range tests {
+		out := make([]byte, tt.n)
+		if err := LongHash(out, []byte("password")); err != nil {
+			t.Errorf("LongHash(%d): %v", tt.n, err)
+			continue
+		}
+		if got := fmt.Sprintf("%x", out); got != tt.want {
+			t.Errorf("LongHash(%d) = %s, want %s", tt.n, got, tt.want)
+		}
+
+		// The input may be split up
+		split := make([]byte, tt.n)
+		LongHash(split, []byte("pass"), nil, []byte("word"))
+		if !bytes.Equal(split, out) {
+			t.Errorf("LongHash(%d) with split input = %x, want %x", tt.n, split, out)
+		}
+	}
+
+	if err := LongHash(nil, []byte("password")); err != ErrInvalidTagLength {
+		t.Errorf("LongHash with empty output: got %v, want %v", err, ErrInvalidTagLength)
+	}
+}
+
+func ExampleLongHash() {
+	// Derive a 100-byte subkey
+	subkey := make([]byte, 100)
+	if err := LongHash(subkey, []byte("master key"), []byte("context")); err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(len(subkey))
+	// Output: 100
+}
+
+// Checks Argon2d with several lanes and segments longer than two blocks,
+// where the first slice of the first pass must not reference other lanes.
+// The expected keys are from the reference implementation (argon2 -d).
+func TestKeyLanes(t *testing.T) {
+	tests := []struct {
+		n, par int
+		mem    int64
+		want   string
+	}{
+		{n: 3, par: 2, mem: 64, want: "cc560d31539dd6fe32a10a023c349388f68fe0e52754960fb80d099e5fe6a29b"},
+		{n: 1, par: 4, mem: 128, want: "87c6e714ae19304a3945433b6786fe5e580b4e7fa1b848773f46655a79c20ad3"},
+		{n: 2, par: 4, mem: 256, want: "3e552c7b5150fa582b9ee3277b5a1399735fe2beeb10795751c33e47593d5528"},
+	}
+	for _, tt := range tests {
+		key, err := Key([]byte("password"), []byte("somesalt"), tt.n, tt.par, tt.mem, 32)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := fmt.Sprintf("%x", key); got != tt.want {
+			t.Errorf("n=%d par=%d mem=%d: got %s, want %s", tt.n, tt.par, tt.mem, got, tt.want)
+		}
+	}
 }
@@ -1,14 +1,13 @@
 package argon2
 
-import (
-	"hash"
+//go:generate go run round_gen.go -output round.go
 
-	"github.com/dchest/blake2b"
+import (
+	"context"
+	"sync"
+	"unsafe"
 )
 
-const version uint32 = 0x13
-const mode = 0 // Argon2d
-
 /*
 
 inputs:
@@ -22,42 +21,149 @@ inputs:
  m memory size
  n iterations
 
+ mode variant (Argon2d, Argon2i, or Argon2id)
+ version algorithm version (0x10 or 0x13)
+
+ threads maximum number of lanes to fill concurrently
+ progress called with the pass, slice, and total number of passes
+          after each slice is filled (optional)
+
 */
 
-type logFunc func(string, ...interface{})
+// A progressFunc is called after each slice of each pass is finished.
+type progressFunc func(pass, slice, total uint32)
+
+// argon2 computes the hash of the inputs into output.
+// If ctx is canceled before the computation is finished,
+// argon2 wipes the matrix and returns ctx.Err().
+// The matrix is allocated with alloc, or on the heap if alloc is nil.
+func argon2(ctx context.Context, output, P, S, K, X []byte, p, m, n uint32, mode Variant, version uint32, threads int, progress progressFunc, tracer Tracer, alloc allocator) error {
+	ws, err := newWorkspace(numBlocks(m, p), p, threads, alloc)
+	if err != nil {
+		return err
+	}
+	defer ws.free()
+	return ws.argon2(ctx, output, P, S, K, X, p, m, n, mode, version, progress, tracer)
+}
+
+// numBlocks returns the number of blocks used for m KiB of memory
+// and p lanes: m rounded down to a multiple of 4*p, but at least 8*p.
+func numBlocks(m, p uint32) uint32 {
+	if p == 0 {
+		panic("argon: internal error: invalid params")
+	}
+	m = m / (4 * p) * (4 * p)
+	if m < 8*p {
+		m = 8 * p
+	}
+	return m
+}
+
+// A workspace holds the memory needed to compute a hash.
+// It can be reused for any computation with the same number of blocks.
+type workspace struct {
+	b       [][128]uint64 // the matrix
+	mem     [][128]uint64 // the allocated memory: b and everything below
+	alloc   allocator     // allocated mem
+	fillers []filler      // scratch space for each goroutine
+	h       *blake2b      // BLAKE2b-512
+	lh      longHash
+
+	scratch *[72]byte   // parameter hash and block index
+	btmp    *[1024]byte // a block, serialized
+}
+
+// Sizes of the workspace's buffers, in blocks
+const (
+	fillerBlocks = int(unsafe.Sizeof(filler{}) / 1024)
+	stateBlocks  = int((unsafe.Sizeof(hashState{}) + 1023) / 1024)
+)
+
+// newWorkspace allocates a workspace for a matrix with m blocks
+// and up to threads goroutines, but no more than one per lane.
+// The matrix is allocated with alloc, or on the heap if alloc is nil.
+func newWorkspace(m, p uint32, threads int, alloc allocator) (*workspace, error) {
+	if threads > int(p) {
+		threads = int(p)
+	}
+	if threads < 1 {
+		threads = 1
+	}
+	if alloc == nil {
+		alloc = heapAllocator{}
+	}
+	// The buffers, the fillers, and the hash state follow the matrix,
+	// so that they get the same treatment from the allocator
+	mem, err := alloc.alloc(int(m) + 2 + threads*fillerBlocks + stateBlocks)
+	if err != nil {
+		return nil, err
+	}
+	fillers := (*[maxPar]filler)(unsafe.Pointer(&mem[m+2]))[:threads:threads]
+	st := (*hashState)(unsafe.Pointer(&mem[int(m)+2+threads*fillerBlocks]))
+	st.init()
+	return &workspace{
+		b:       mem[:m:m],
+		mem:     mem,
+		alloc:   alloc,
+		fillers: fillers,
+		btmp:    (*[1024]byte)(unsafe.Pointer(&mem[m])),
+		scratch: (*[72]byte)(unsafe.Pointer(&mem[m+1])),
+		h:       &st.h,
+		lh:      longHash{st: st},
+	}, nil
+}
+
+// free releases the matrix. The workspace must not be used afterwards.
+func (ws *workspace) free() {
+	ws.alloc.free(ws.mem)
+	ws.b, ws.mem = nil, nil
+	ws.fillers = nil
+	ws.scratch, ws.btmp = nil, nil
+	ws.h, ws.lh = nil, longHash{}
+}
 
-func argon2(output, P, S, K, X []byte, p, m, n uint32, logf logFunc) {
+// argon2 is like the argon2 function, but uses the workspace's memory.
+// The parameters must match the size of the workspace.
+func (ws *workspace) argon2(ctx context.Context, output, P, S, K, X []byte, p, m, n uint32, mode Variant, version uint32, progress progressFunc, tracer Tracer) error {
 	if p == 0 || m == 0 || n == 0 {
 		panic("argon: internal error: invalid params")
 	}
-	if m%(p*4) != 0 {
-		panic("argon: internal error: invalid m")
+	if mode != Argon2d && mode != Argon2i && mode != Argon2id {
+		panic("argon: internal error: invalid mode")
+	}
+	if version != Version10 && version != Version13 {
+		panic("argon: internal error: invalid version")
 	}
 
+	// Wipe everything derived from the password before returning
+	defer ws.wipe()
+
+	// The parameter hash uses the original value of m
 	m0 := m
-	if m < 8*p {
-		m = 8 * p
+	m = numBlocks(m, p)
+	if uint32(len(ws.b)) != m {
+		panic("argon: internal error: wrong workspace size")
 	}
 
 	// Argon2 operates over a matrix of 1024-byte blocks
-	b := make([][128]uint64, m)
+	b := ws.b
 	q := m / p // length of each lane
 	g := q / 4 // length of each segment
 
-	var scratch [72]byte
-	var btmp [1024]byte
-	var btmp2 [128]uint64
+	scratch := ws.scratch
+	btmp := ws.btmp
 
 	// Compute a hash of all the input parameters
-	h := blake2b.New512()
-	lh := newLongHash(h)
+	h := ws.h
+	lh := &ws.lh
+	h.Reset()
 
 	put32(scratch[0:4], p)
 	put32(scratch[4:8], uint32(len(output)))
 	put32(scratch[8:12], m0)
 	put32(scratch[12:16], n)
 	put32(scratch[16:20], version)
-	put32(scratch[20:24], mode)
+	put32(scratch[20:24], uint32(mode))
 	h.Write(scratch[:24])
 
 	put32(scratch[0:4], uint32(len(P)))
@@ -101,13 +207,20 @@ func argon2(output, P, S, K, X []byte, p, m, n uint32, logf logFunc) {
 		}
 	}
 
-	if logf != nil {
-		logf("Iterations: %d, Memory: %d KiB, Parallelism: %d lanes, Tag length: %d bytes", n, m, p, len(output))
-		logf("Password[%d]: % x", len(P), P)
-		logf("Nonce[%d]: % x", len(S), S)
-		logf("Secret[%d]: % x", len(K), K)
-		logf("Associated data[%d]: % x", len(X), X)
-		logf("Input hash: % x", scratch[:64])
+	if tracer != nil {
+		tracer.Trace(InputHashEvent{
+			Variant:   mode,
+			Version:   version,
+			Lanes:     p,
+			Memory:    m0,
+			Time:      n,
+			TagLength: uint32(len(output)),
+			Hash:      scratch[:64],
+		})
+		for lane := uint32(0); lane < p; lane++ {
+			tracer.Trace(BlockEvent{Pass: 0, Slice: 0, Lane: lane, Index: 0, Block: &b[lane*q+0]})
+			tracer.Trace(BlockEvent{Pass: 0, Slice: 0, Lane: lane, Index: 1, Block: &b[lane*q+1]})
+		}
 	}
 
 	for i := range scratch {
@@ -118,36 +231,30 @@ func argon2(output, P, S, K, X []byte, p, m, n uint32, logf logFunc) {
 	}
 
 	// Get down to business
+	in := instance{b: b, p: p, q: q, g: g, m: m, n: n, mode: mode, version: version, done: ctx.Done(), tracer: tracer}
+	fillers := ws.fillers
+	if tracer != nil {
+		// Keep the events in order
+		fillers = fillers[:1]
+	}
 	for k := uint32(0); k < n; k++ {
-		if logf != nil {
-			logf("")
-			logf(" After pass %d:", k)
-		}
 		for slice := uint32(0); slice < 4; slice++ {
-			for lane := uint32(0); lane < p; lane++ {
-				i := uint32(0)
-				if k == 0 && slice == 0 {
-					i = 2
-				}
-				j := lane*q + slice*g + i
-				for ; i < g; i, j = i+1, j+1 {
-					prev := j - 1
-					if i == 0 && slice == 0 {
-						prev = lane*q + q - 1
-					}
-
-					rand := b[prev][0]
-					rslice, rlane, ri := index(rand, q, g, p, k, slice, lane, i, logf)
-					j0 := rlane*q + rslice*g + ri
-
-					block(&b[j], &btmp2, &b[prev], &b[j0])
+			if len(fillers) > 1 {
+				in.fillSliceParallel(fillers, k, slice)
+			} else {
+				for lane := uint32(0); lane < p && !in.canceled(); lane++ {
+					in.fillSegment(&fillers[0], k, slice, lane)
 				}
 			}
-		}
-		if logf != nil {
-			for i := range b {
-				logf("  Block %.4d [0]: %x", i, b[i][0])
+			if in.canceled() {
+				return ctx.Err()
 			}
+			if progress != nil {
+				progress(k, slice, n)
+			}
+		}
+		if tracer != nil {
+			tracer.Trace(PassEvent{Pass: k, Blocks: b})
 		}
 	}
 
@@ -159,29 +266,152 @@ func argon2(output, P, S, K, X []byte, p, m, n uint32, logf logFunc) {
 	}
 
 	// Output
-	for i, v := range b[m-1] {
-		btmp[i*8] = uint8(v)
-		btmp[i*8+1] = uint8(v >> 8)
-		btmp[i*8+2] = uint8(v >> 16)
-		btmp[i*8+3] = uint8(v >> 24)
-		btmp[i*8+4] = uint8(v >> 32)
-		btmp[i*8+5] = uint8(v >> 40)
-		btmp[i*8+6] = uint8(v >> 48)
-		btmp[i*8+7] = uint8(v >> 56)
-	}
-	if logf != nil {
-		logf("Final block: %x", btmp[:])
+	putBlock(btmp[:], &b[m-1])
+	if tracer != nil {
+		tracer.Trace(FinalBlockEvent{Block: btmp})
 	}
 	lh.Init(len(output))
 	lh.Write(btmp[:])
 	lh.Hash(output)
-	if logf != nil {
-		logf("Output: % X", output)
+	return nil
+}
+
+// wipe overwrites all of the password-dependent memory in the workspace.
+//
+// Note that Go may leave copies of some intermediate values
+// on the stack, which cannot be wiped.
+func (ws *workspace) wipe() {
+	for i := range ws.b {
+		ws.b[i] = [128]uint64{}
+	}
+	for i := range ws.fillers {
+		ws.fillers[i] = filler{}
 	}
+	*ws.scratch = [72]byte{}
+	*ws.btmp = [1024]byte{}
+	ws.lh.wipe()
 }
 
-func index(rand uint64, q, g, p, k, slice, lane, i uint32, logf logFunc) (rslice, rlane, ri uint32) {
+// An instance describes the matrix being filled.
+type instance struct {
+	b       [][128]uint64
+	p, q, g uint32 // number of lanes, lane length, segment length
+	m, n    uint32 // total number of blocks, number of passes
+	mode    Variant
+	version uint32
+	done    <-chan struct{} // closed when the computation should be abandoned
+	tracer  Tracer
+}
+
+// canceled reports whether the computation has been canceled.
+func (in *instance) canceled() bool {
+	select {
+	case <-in.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// A filler holds the scratch space needed to fill a segment.
+// Each goroutine filling segments needs its own.
+type filler struct {
+	t [128]uint64 // scratch space for block
+
+	// Data-independent addressing state (Argon2i and Argon2id)
+	zero, input, addr, atmp [128]uint64
+}
+
+// fillSliceParallel computes all the segments in the given slice of pass k,
+// using one goroutine per filler.
+// Segments in the same slice are independent of each other,
+// but every segment in a slice must be finished before the next slice starts.
+func (in instance) fillSliceParallel(fillers []filler, k, slice uint32) {
+	var wg sync.WaitGroup
+	wg.Add(len(fillers))
+	for w := range fillers {
+		go func(f *filler, lane uint32) {
+			for ; lane < in.p && !in.canceled(); lane += uint32(len(fillers)) {
+				in.fillSegment(f, k, slice, lane)
+			}
+			wg.Done()
+		}(&fillers[w], uint32(w))
+	}
+	wg.Wait()
+}
+
+// fillSegment computes the blocks in the given segment of pass k.
+func (in *instance) fillSegment(f *filler, k, slice, lane uint32) {
+	b, p, q, g := in.b, in.p, in.q, in.g
+	mode := in.mode
+
+	indep := mode == Argon2i || mode == Argon2id && k == 0 && slice < 2
+	i := uint32(0)
+	if k == 0 && slice == 0 {
+		i = 2
+	}
+	if indep {
+		f.input[0] = uint64(k)
+		f.input[1] = uint64(lane)
+		f.input[2] = uint64(slice)
+		f.input[3] = uint64(in.m)
+		f.input[4] = uint64(in.n)
+		f.input[5] = uint64(mode)
+		f.input[6] = 0
+	}
+	j := lane*q + slice*g + i
+	for start := i; i < g; i, j = i+1, j+1 {
+		prev := j - 1
+		if i == 0 && slice == 0 {
+			prev = lane*q + q - 1
+		}
+
+		var rand uint64
+		if indep {
+			if i == start || i%128 == 0 {
+				nextAddresses(&f.addr, &f.atmp, &f.input, &f.zero, &f.t)
+			}
+			rand = f.addr[i%128]
+		} else {
+			rand = b[prev][0]
+		}
+		rslice, rlane, ri := index(rand, q, g, p, k, slice, lane, i)
+		j0 := rlane*q + rslice*g + ri
+		if in.tracer != nil {
+			in.tracer.Trace(ReferenceEvent{Pass: k, Slice: slice, Lane: lane, Index: slice*g + i, Rand: rand, RefLane: rlane, RefIndex: rslice*g + ri})
+		}
+
+		if k == 0 || in.version == Version10 {
+			// The first pass overwrites whatever was left in the matrix.
+			// Version 1.0 always overwrites blocks
+			// instead of XORing into them.
+			b[j] = [128]uint64{}
+		}
+		block(&b[j], &f.t, &b[prev], &b[j0])
+		if in.tracer != nil {
+			in.tracer.Trace(BlockEvent{Pass: k, Slice: slice, Lane: lane, Index: slice*g + i, Block: &b[j]})
+		}
+	}
+}
+
+// nextAddresses increments the counter in the input block
+// and computes the next block of pseudo-random values
+// for data-independent addressing: addr = G(0, G(0, input)).
+func nextAddresses(addr, tmp, input, zero, t *[128]uint64) {
+	input[6]++
+	*tmp = [128]uint64{}
+	block(tmp, t, zero, input)
+	*addr = [128]uint64{}
+	block(addr, t, zero, tmp)
+}
+
+func index(rand uint64, q, g, p, k, slice, lane, i uint32) (rslice, rlane, ri uint32) {
 	rlane = uint32(rand>>32) % p
+	if k == 0 && slice == 0 {
+		// The first slice of the first pass can only
+		// reference blocks in the current lane
+		rlane = lane
+	}
 
 	var start, max uint32
 	if k == 0 {
@@ -214,45 +444,61 @@ func index(rand uint64, q, g, p, k, slice, lane, i uint32, logf logFunc) (rslice
 	phi = phi * uint64(max) >> 32
 	ri = uint32((uint64(start) + uint64(max) - 1 - phi) % uint64(q))
 
-	if logf != nil {
-		i0 := lane*q + slice*g + i
-		j0 := rlane*q + ri
-		logf("  i = %d(%d,%d,%d), rand = %d, max = %d, start = %d, phi = %d, j = %d(%d,%d,%d)", i0, lane, slice, i, rand, max, start, phi, j0, rlane, rslice, ri)
-	}
-
 	return rslice, rlane, ri
 }
 
-type longHash struct {
-	buf [64]uint8
-	h   hash.Hash
-	h0  hash.Hash // large hash
-	h1  hash.Hash // small hash
-	n   int
+// hashState holds the state of the hash functions,
+// which is derived from the password.
+// It contains no pointers, so that it can be placed
+// in memory from an allocator along with the matrix.
+type hashState struct {
+	h     blake2b   // BLAKE2b-512
+	small blake2b   // a hash with a digest size less than 64 bytes
+	buf   [64]uint8 // the chain of hashes in longHash
 }
 
-func newLongHash(h hash.Hash) *longHash {
-	return &longHash{h: h}
+// init readies the hashes for use.
+func (st *hashState) init() {
+	st.h.init(64, nil)
+}
+
+type longHash struct {
+	st *hashState
+	h0 *blake2b // large hash
+	h1 *blake2b // small hash
+	n  int
 }
 
 // Init readies longHash for an output of length n.
 func (lh *longHash) Init(n int) {
 	lh.n = n
-	lh.h.Reset()
-	lh.h0 = lh.h
-	lh.h1 = lh.h
-	var err error
+	lh.st.h.Reset()
+	lh.h0 = &lh.st.h
+	lh.h1 = &lh.st.h
 	if n < 64 {
-		lh.h0, err = blake2b.New(&blake2b.Config{Size: uint8(n)})
+		lh.h0 = lh.sized(n)
 	} else if n%64 != 0 {
-		n := 33 + (n+31)%32
-		lh.h1, err = blake2b.New(&blake2b.Config{Size: uint8(n)})
+		lh.h1 = lh.sized(33 + (n+31)%32)
 	}
-	if err != nil {
-		panic(err)
+	put32(lh.st.buf[:4], uint32(n))
+	lh.Write(lh.st.buf[:4])
+}
+
+// sized returns a reset BLAKE2b hash with a digest size of n bytes.
+func (lh *longHash) sized(n int) *blake2b {
+	lh.st.small.init(n, nil)
+	return &lh.st.small
+}
+
+// wipe clears the internal state of the hashes.
+func (lh *longHash) wipe() {
+	lh.st.buf = [64]uint8{}
+	lh.st.h.Reset()
+	if lh.st.small.size != 0 {
+		lh.st.small.Reset()
 	}
-	put32(lh.buf[:4], uint32(n))
-	lh.Write(lh.buf[:4])
+	lh.h0 = nil
+	lh.h1 = nil
 }
 
 func (lh *longHash) Write(b []byte) {
@@ -269,21 +515,36 @@ func (lh *longHash) Hash(out []byte) {
 		return
 	}
 
-	lh.h0.Sum(lh.buf[:0])
-	copy(out, lh.buf[:32])
+	lh.h0.Sum(lh.st.buf[:0])
+	copy(out, lh.st.buf[:32])
 	for out = out[32:]; len(out) > 64; out = out[32:] {
 		lh.h0.Reset()
-		lh.h0.Write(lh.buf[:])
-		lh.h0.Sum(lh.buf[:0])
-		copy(out, lh.buf[:32])
+		lh.h0.Write(lh.st.buf[:])
+		lh.h0.Sum(lh.st.buf[:0])
+		copy(out, lh.st.buf[:32])
 	}
 	if lh.h0 == lh.h1 {
 		lh.h1.Reset()
 	}
-	lh.h1.Write(lh.buf[:])
+	lh.h1.Write(lh.st.buf[:])
 	lh.h1.Sum(out[:0])
 }
 
+// putBlock serializes a block in little-endian byte order.
+func putBlock(out []byte, b *[128]uint64) {
+	_ = out[1023]
+	for i, v := range b {
+		out[i*8] = uint8(v)
+		out[i*8+1] = uint8(v >> 8)
+		out[i*8+2] = uint8(v >> 16)
+		out[i*8+3] = uint8(v >> 24)
+		out[i*8+4] = uint8(v >> 32)
+		out[i*8+5] = uint8(v >> 40)
+		out[i*8+6] = uint8(v >> 48)
+		out[i*8+7] = uint8(v >> 56)
+	}
+}
+
 func put32(b []uint8, v uint32) {
 	_ = b[3]
 	b[0] = uint8(v)
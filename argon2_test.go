@@ -2,7 +2,11 @@ package argon2
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"testing"
+	"time"
+	"unsafe"
 )
 
 // Repeat returns a slice containing n copies of v.
@@ -14,23 +18,35 @@ func repeat(v uint8, n int) []byte {
 	return b
 }
 
-// Runs argon2 with logging enabled, for debugging purposes
+// Runs argon2 with tracing enabled, for debugging purposes
 func TestDebug(t *testing.T) {
 	var out [8]uint8
-	argon2(out[:], repeat(0, 16), repeat(1, 8), nil, nil, 1, 8, 3, t.Logf)
+	argon2(context.Background(), out[:], repeat(0, 16), repeat(1, 8), nil, nil, 1, 8, 3, Argon2d, Version13, 1, nil, NewJSONTracer(logWriter{t}), nil)
 }
 
-// Runs the test vector from the official repository
+// Runs the test vectors from the official repository
 func TestArgon_Vector(t *testing.T) {
 	msg := repeat(0x1, 32)
 	salt := repeat(0x2, 16)
 	key := repeat(0x3, 8)
 	data := repeat(0x4, 12)
-	want := []byte{0x51, 0x2b, 0x39, 0x1b, 0x6f, 0x11, 0x62, 0x97, 0x53, 0x71, 0xd3, 0x09, 0x19, 0x73, 0x42, 0x94, 0xf8, 0x68, 0xe3, 0xbe, 0x39, 0x84, 0xf3, 0xc1, 0xa1, 0x3a, 0x4d, 0xb9, 0xfa, 0xbe, 0x4a, 0xcb}
-	out := make([]byte, len(want))
-	argon2(out, msg, salt, key, data, 4, 32, 3, t.Logf)
-	if !bytes.Equal(want, out) {
-		t.Errorf("got % x, want % x\n", out, want)
+	var tests = []struct {
+		mode    Variant
+		version uint32
+		want    []byte
+	}{
+		{mode: Argon2d, version: Version10, want: []byte{0x96, 0xa9, 0xd4, 0xe5, 0xa1, 0x73, 0x40, 0x92, 0xc8, 0x5e, 0x29, 0xf4, 0x10, 0xa4, 0x59, 0x14, 0xa5, 0xdd, 0x1f, 0x5c, 0xbf, 0x08, 0xb2, 0x67, 0x0d, 0xa6, 0x8a, 0x02, 0x85, 0xab, 0xf3, 0x2b}},
+		{mode: Argon2i, version: Version10, want: []byte{0x87, 0xae, 0xed, 0xd6, 0x51, 0x7a, 0xb8, 0x30, 0xcd, 0x97, 0x65, 0xcd, 0x82, 0x31, 0xab, 0xb2, 0xe6, 0x47, 0xa5, 0xde, 0xe0, 0x8f, 0x7c, 0x05, 0xe0, 0x2f, 0xcb, 0x76, 0x33, 0x35, 0xd0, 0xfd}},
+		{mode: Argon2d, version: Version13, want: []byte{0x51, 0x2b, 0x39, 0x1b, 0x6f, 0x11, 0x62, 0x97, 0x53, 0x71, 0xd3, 0x09, 0x19, 0x73, 0x42, 0x94, 0xf8, 0x68, 0xe3, 0xbe, 0x39, 0x84, 0xf3, 0xc1, 0xa1, 0x3a, 0x4d, 0xb9, 0xfa, 0xbe, 0x4a, 0xcb}},
+		{mode: Argon2i, version: Version13, want: []byte{0xc8, 0x14, 0xd9, 0xd1, 0xdc, 0x7f, 0x37, 0xaa, 0x13, 0xf0, 0xd7, 0x7f, 0x24, 0x94, 0xbd, 0xa1, 0xc8, 0xde, 0x6b, 0x01, 0x6d, 0xd3, 0x88, 0xd2, 0x99, 0x52, 0xa4, 0xc4, 0x67, 0x2b, 0x6c, 0xe8}},
+		{mode: Argon2id, version: Version13, want: []byte{0x0d, 0x64, 0x0d, 0xf5, 0x8d, 0x78, 0x76, 0x6c, 0x08, 0xc0, 0x37, 0xa3, 0x4a, 0x8b, 0x53, 0xc9, 0xd0, 0x1e, 0xf0, 0x45, 0x2d, 0x75, 0xb6, 0x5e, 0xb5, 0x25, 0x20, 0xe9, 0x6b, 0x01, 0xe6, 0x59}},
+	}
+	for _, tt := range tests {
+		out := make([]byte, len(tt.want))
+		argon2(context.Background(), out, msg, salt, key, data, 4, 32, 3, tt.mode, tt.version, 1, nil, nil, nil)
+		if !bytes.Equal(tt.want, out) {
+			t.Errorf("%s v=%#x: got % x, want % x\n", tt.mode, tt.version, out, tt.want)
+		}
 	}
 }
 
@@ -92,11 +108,123 @@ func TestArgon(t *testing.T) {
 	salt := repeat(0x1, 8)
 
 	for _, tt := range tests {
-		out := make([]byte, len(tt.want))
-		argon2(out, msg, salt, nil, nil, tt.par, tt.mem, tt.n, nil)
-		if !bytes.Equal(out, tt.want) {
-			t.Errorf("n=%d, mem=%d, par=%d, len=%d: got % x, want % x\n", tt.n, tt.mem, tt.par, len(tt.want), out, tt.want)
+		for _, threads := range []int{1, 3, 8} {
+			out := make([]byte, len(tt.want))
+			argon2(context.Background(), out, msg, salt, nil, nil, tt.par, tt.mem, tt.n, Argon2d, Version13, threads, nil, nil, nil)
+			if !bytes.Equal(out, tt.want) {
+				t.Errorf("n=%d, mem=%d, par=%d, len=%d, threads=%d: got % x, want % x\n", tt.n, tt.mem, tt.par, len(tt.want), threads, out, tt.want)
+			}
+		}
+	}
+}
+
+// Checks that no password-dependent memory is left behind
+func TestWipe(t *testing.T) {
+	pw := repeat(0x0, 200) // longer than a BLAKE2b block
+	salt := repeat(0x1, 8)
+	key := repeat(0x3, 8)
+	data := repeat(0x4, 12)
+	for _, alloc := range []allocator{heapAllocator{}, offHeapAllocator, secureAllocator} {
+		for _, mode := range []Variant{Argon2d, Argon2i, Argon2id} {
+			for _, outlen := range []int{32, 100} {
+				ws, err := newWorkspace(numBlocks(64, 2), 2, 2, alloc)
+				if errors.Is(err, ErrMemoryLock) {
+					t.Logf("%T: %v", alloc, err)
+					continue
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				out := make([]byte, outlen)
+				err = ws.argon2(context.Background(), out, pw, salt, key, data, 2, 64, 3, mode, Version13, nil, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				checkWiped(t, ws)
+				ws.free()
+			}
+		}
+	}
+
+	// Cancel in the middle of the computation
+	ws, err := newWorkspace(numBlocks(1024, 2), 2, 2, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	out := make([]byte, 32)
+	err = ws.argon2(ctx, out, pw, salt, key, data, 2, 1024, 1000, Argon2id, Version13, nil, nil)
+	if err != context.DeadlineExceeded {
+		t.Errorf("got %v, want %v", err, context.DeadlineExceeded)
+	}
+	checkWiped(t, ws)
+}
+
+// Checks that everything derived from the password is placed
+// in the memory from the allocator
+func TestWorkspaceLayout(t *testing.T) {
+	for _, threads := range []int{1, 2, 4} {
+		ws, err := newWorkspace(numBlocks(64, 4), 4, threads, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		start := uintptr(unsafe.Pointer(&ws.mem[0]))
+		end := start + uintptr(len(ws.mem))*1024
+		inside := func(name string, p unsafe.Pointer, size uintptr) {
+			if uintptr(p) < start || uintptr(p)+size > end {
+				t.Errorf("threads=%d: %s is outside the allocated memory", threads, name)
+			}
+		}
+		if len(ws.fillers) != threads {
+			t.Errorf("threads=%d: got %d fillers", threads, len(ws.fillers))
 		}
+		for i := range ws.fillers {
+			inside("filler", unsafe.Pointer(&ws.fillers[i]), unsafe.Sizeof(filler{}))
+		}
+		inside("hash state", unsafe.Pointer(ws.lh.st), unsafe.Sizeof(hashState{}))
+		inside("btmp", unsafe.Pointer(ws.btmp), 1024)
+		inside("scratch", unsafe.Pointer(ws.scratch), 72)
+		ws.free()
+	}
+}
+
+func checkWiped(t *testing.T, ws *workspace) {
+	t.Helper()
+	for i := range ws.b {
+		if ws.b[i] != [128]uint64{} {
+			t.Errorf("block %d was not wiped", i)
+			break
+		}
+	}
+	for i := range ws.fillers {
+		if ws.fillers[i] != (filler{}) {
+			t.Errorf("filler %d was not wiped", i)
+		}
+	}
+	if *ws.scratch != [72]byte{} {
+		t.Errorf("scratch was not wiped")
+	}
+	if *ws.btmp != [1024]byte{} {
+		t.Errorf("btmp was not wiped")
+	}
+	if ws.lh.st.buf != [64]byte{} {
+		t.Errorf("longHash buffer was not wiped")
+	}
+	checkHashWiped(t, "h", &ws.lh.st.h)
+	if ws.lh.st.small.size != 0 {
+		checkHashWiped(t, "small", &ws.lh.st.small)
+	}
+}
+
+// checkHashWiped inspects the internal state of a BLAKE2b hash
+func checkHashWiped(t *testing.T, name string, h *blake2b) {
+	t.Helper()
+	if h.x != [blake2bBlockSize]byte{} || h.m != [16]uint64{} {
+		t.Errorf("%s: buffer was not wiped", name)
+	}
+	if want := newBlake2b(h.size, nil); h.h != want.h || h.t != want.t {
+		t.Errorf("%s: chain value was not reset", name)
 	}
 }
 
@@ -105,7 +233,7 @@ func TestAllocs(t *testing.T) {
 	salt := repeat(0x1, 8)
 	out := make([]byte, 32)
 	allocs := testing.AllocsPerRun(100, func() {
-		argon2(out, pw, salt, nil, nil, 4, 32, 3, nil)
+		argon2(context.Background(), out, pw, salt, nil, nil, 4, 32, 3, Argon2d, Version13, 1, nil, nil, nil)
 	})
 	if allocs > 6 {
 		t.Errorf("%v allocs, want <=6", allocs)
@@ -113,12 +241,16 @@ func TestAllocs(t *testing.T) {
 }
 
 func benchArgon(b *testing.B, par uint8, mem, n uint32) {
+	benchArgonThreads(b, par, mem, n, 1)
+}
+
+func benchArgonThreads(b *testing.B, par uint8, mem, n uint32, threads int) {
 	msg := repeat(0x0, 16)
 	salt := repeat(0x1, 8)
 	out := make([]byte, 8)
 	b.SetBytes(int64(mem) << 10)
 	for i := 0; i < b.N; i++ {
-		argon2(out, msg, salt, nil, nil, uint32(par), mem, n, nil)
+		argon2(context.Background(), out, msg, salt, nil, nil, uint32(par), mem, n, Argon2d, Version13, threads, nil, nil, nil)
 	}
 }
 
@@ -134,4 +266,7 @@ func BenchmarkArgon1P(b *testing.B) { benchArgon(b, 1, 64, 3) }
 func BenchmarkArgon2P(b *testing.B) { benchArgon(b, 2, 64, 3) }
 func BenchmarkArgon4P(b *testing.B) { benchArgon(b, 4, 64, 3) }
 
+func BenchmarkArgon4P4T(b *testing.B) { benchArgonThreads(b, 4, 4096, 3, 4) }
+func BenchmarkArgon4P1T(b *testing.B) { benchArgonThreads(b, 4, 4096, 3, 1) }
+
 //func BenchmarkArgon_4MiB(b *testing.B) { benchArgon(b, 1, 4096, 3) }
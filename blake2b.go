@@ -0,0 +1,129 @@
+package argon2
+
+//go:generate go run round_gen.go -blake2b -output blake2b_round.go
+
+// BLAKE2b, as specified in RFC 7693.
+
+const (
+	blake2bBlockSize = 128
+	blake2bSize      = 64 // maximum digest size
+	blake2bKeySize   = 64 // maximum key size
+)
+
+var blake2bIV = [8]uint64{
+	0x6a09e667f3bcc908, 0xbb67ae8584caa73b, 0x3c6ef372fe94f82b, 0xa54ff53a5f1d36f1,
+	0x510e527fade682d1, 0x9b05688c2b3e6c1f, 0x1f83d9abfb41bd6b, 0x5be0cd19137e2179,
+}
+
+// blake2b is a BLAKE2b hash with a digest size of 1 to 64 bytes,
+// optionally keyed. It implements hash.Hash.
+//
+// Unlike most hashes, Reset clears any buffered input,
+// so that no input is left behind in memory.
+type blake2b struct {
+	h  [8]uint64              // chain value
+	t  [2]uint64              // number of bytes compressed so far
+	x  [blake2bBlockSize]byte // buffered input
+	nx int                    // number of bytes in x
+	m  [16]uint64             // x as words, for the compression function
+
+	size   int
+	key    [blake2bBlockSize]byte // the key, padded with zeros to a full block
+	keyLen int
+}
+
+// newBlake2b returns a BLAKE2b hash with a digest size of size bytes.
+// If key is not empty, the hash is keyed.
+// It panics if size is not between 1 and 64 or if the key is longer than 64 bytes.
+func newBlake2b(size int, key []byte) *blake2b {
+	d := new(blake2b)
+	d.init(size, key)
+	return d
+}
+
+// init sets up d in place, like newBlake2b.
+func (d *blake2b) init(size int, key []byte) {
+	if size < 1 || size > blake2bSize {
+		panic("argon2: invalid BLAKE2b digest size")
+	}
+	if len(key) > blake2bKeySize {
+		panic("argon2: BLAKE2b key too long")
+	}
+	d.size = size
+	d.keyLen = len(key)
+	d.key = [blake2bBlockSize]byte{}
+	copy(d.key[:], key)
+	d.Reset()
+}
+
+func (d *blake2b) Size() int      { return d.size }
+func (d *blake2b) BlockSize() int { return blake2bBlockSize }
+
+func (d *blake2b) Reset() {
+	d.h = blake2bIV
+	d.h[0] ^= 0x01010000 ^ uint64(d.keyLen)<<8 ^ uint64(d.size)
+	d.t = [2]uint64{}
+	d.m = [16]uint64{}
+	d.x = [blake2bBlockSize]byte{}
+	d.nx = 0
+	if d.keyLen > 0 {
+		// The key is hashed as a block of its own
+		d.x = d.key
+		d.nx = blake2bBlockSize
+	}
+}
+
+func (d *blake2b) Write(p []byte) (int, error) {
+	n := len(p)
+	// The last block must be compressed with the finalization flag,
+	// so a full buffer is only compressed once there is more input.
+	if d.nx > 0 {
+		left := blake2bBlockSize - d.nx
+		if len(p) <= left {
+			d.nx += copy(d.x[d.nx:], p)
+			return n, nil
+		}
+		copy(d.x[d.nx:], p[:left])
+		d.compress(d.x[:])
+		p = p[left:]
+		d.nx = 0
+	}
+	for len(p) > blake2bBlockSize {
+		d.compress(p[:blake2bBlockSize])
+		p = p[blake2bBlockSize:]
+	}
+	d.nx = copy(d.x[:], p)
+	return n, nil
+}
+
+// Sum appends the digest to b. It does not change the hash state.
+func (d *blake2b) Sum(b []byte) []byte {
+	for i := d.nx; i < len(d.x); i++ {
+		d.x[i] = 0
+	}
+	h := d.h
+	t0, t1 := d.t[0]+uint64(d.nx), d.t[1]
+	if t0 < uint64(d.nx) {
+		t1++
+	}
+	for i := range d.m {
+		d.m[i] = read64(d.x[i*8:])
+	}
+	blake2bCompress(&h, &d.m, t0, t1, ^uint64(0))
+	for i := 0; i < d.size; i++ {
+		b = append(b, byte(h[i/8]>>(8*uint(i%8))))
+	}
+	return b
+}
+
+// compress compresses a full block of input which is not the last.
+func (d *blake2b) compress(p []byte) {
+	d.t[0] += blake2bBlockSize
+	if d.t[0] < blake2bBlockSize {
+		d.t[1]++
+	}
+	for i := range d.m {
+		d.m[i] = read64(p[i*8:])
+	}
+	blake2bCompress(&d.h, &d.m, d.t[0], d.t[1], 0)
+}
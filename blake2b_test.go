@@ -0,0 +1,128 @@
+package argon2
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestBlake2b(t *testing.T) {
+	// From RFC 7693, Appendix A and the reference test vectors
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", "786a02f742015903c6c6fd852552d272912f4740e15847618a86e217f71f5419d25e1031afee585313896444934eb04b903a685b1448b755d56f701afe9be2ce"},
+		{"abc", "ba80a53f981c4d0d6a2797b69f12f6e94c212f14685ac4b74b12bb6fdbffa2d17d87c5392aab792dc252d5de4533cc9518d38aa8dbf1925ab92386edd4009923"},
+	}
+	for _, tt := range tests {
+		h := newBlake2b(64, nil)
+		h.Write([]byte(tt.in))
+		if got := hex.EncodeToString(h.Sum(nil)); got != tt.want {
+			t.Errorf("BLAKE2b-512(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestBlake2bKeyed(t *testing.T) {
+	// From blake2b-kat.txt in the reference implementation
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{0, "10ebb67700b1868efb4417987acf4690ae9d972fb7a590c2f02871799aaa4786b5e996e8f0f4eb981fc214b005f42d2ff4233499391653df7aefcbc13fc51568"},
+		{1, "961f6dd1e4dd30f63901690c512e78e4b45e4742ed197c3c5e45c549fd25f2e4187b0bc9fe30492b16b0d0bc4ef9b0f34c7003fac09a5ef1532e69430234cebd"},
+		{127, "76d2d819c92bce55fa8e092ab1bf9b9eab237a25267986cacf2b8ee14d214d730dc9a5aa2d7b596e86a1fd8fa0804c77402d2fcd45083688b218b1cdfa0dcbcb"},
+		{128, "72065ee4dd91c2d8509fa1fc28a37c7fc9fa7d5b3f8ad3d0d7a25626b57b1b44788d4caf806290425f9890a3a2a35a905ab4b37acfd0da6e4517b2525c9651e4"},
+		{129, "64475dfe7600d7171bea0b394e27c9b00d8e74dd1e416a79473682ad3dfdbb706631558055cfc8a40e07bd015a4540dcdea15883cbbf31412df1de1cd4152b91"},
+		{255, "142709d62e28fcccd0af97fad0f8465b971e82201dc51070faa0372aa43e92484be1c1e73ba10906d5d1853db6a4106e0a7bf9800d373d6dee2d46d62ef2a461"},
+	}
+	key := make([]byte, 64)
+	msg := make([]byte, 256)
+	for i := range msg {
+		msg[i] = uint8(i)
+	}
+	copy(key, msg)
+	h := newBlake2b(64, key)
+	for _, tt := range tests {
+		h.Reset()
+		h.Write(msg[:tt.n])
+		if got := hex.EncodeToString(h.Sum(nil)); got != tt.want {
+			t.Errorf("keyed BLAKE2b-512 of %d bytes = %s, want %s", tt.n, got, tt.want)
+		}
+	}
+}
+
+// Runs the self-test from RFC 7693, Appendix E,
+// which covers several digest sizes, input lengths, and keys
+func TestBlake2bSelfTest(t *testing.T) {
+	// Deterministic sequences (Fibonacci generator)
+	seq := func(n int, seed uint32) []byte {
+		out := make([]byte, n)
+		a := 0xDEAD4BAD * seed
+		b := uint32(1)
+		for i := range out {
+			t := a + b
+			a = b
+			b = t
+			out[i] = uint8(t >> 24)
+		}
+		return out
+	}
+
+	const want = "c23a7800d98123bd10f506c61e29da5603d763b8bbad2e737f5e765a7bccd475"
+	ctx := newBlake2b(32, nil)
+	for _, outlen := range []int{20, 32, 48, 64} {
+		for _, inlen := range []int{0, 3, 128, 129, 255, 1024} {
+			in := seq(inlen, uint32(inlen))
+			h := newBlake2b(outlen, nil)
+			h.Write(in)
+			ctx.Write(h.Sum(nil))
+
+			h = newBlake2b(outlen, seq(outlen, uint32(outlen)))
+			h.Write(in)
+			ctx.Write(h.Sum(nil))
+		}
+	}
+	if got := hex.EncodeToString(ctx.Sum(nil)); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+// Checks that the digest does not depend on how the input is split
+func TestBlake2bWrite(t *testing.T) {
+	msg := make([]byte, 1000)
+	for i := range msg {
+		msg[i] = uint8(i * 7)
+	}
+	h := newBlake2b(64, nil)
+	h.Write(msg)
+	want := h.Sum(nil)
+	for _, split := range []int{1, 3, 64, 127, 128, 129, 256, 999} {
+		h.Reset()
+		for p := msg; len(p) > 0; {
+			n := split
+			if n > len(p) {
+				n = len(p)
+			}
+			h.Write(p[:n])
+			// Sum must not change the state
+			h.Sum(nil)
+			p = p[n:]
+		}
+		if got := h.Sum(nil); string(got) != string(want) {
+			t.Errorf("split %d: got %x, want %x", split, got, want)
+		}
+	}
+}
+
+func BenchmarkBlake2b(b *testing.B) {
+	buf := make([]byte, 1024)
+	h := newBlake2b(64, nil)
+	var sum [64]byte
+	b.SetBytes(int64(len(buf)))
+	for i := 0; i < b.N; i++ {
+		h.Reset()
+		h.Write(buf)
+		h.Sum(sum[:0])
+	}
+}
@@ -0,0 +1,60 @@
+//go:build amd64 && !purego
+// +build amd64,!purego
+
+package argon2
+
+var (
+	useSSE4 = hasSSE4()
+	useAVX2 = hasAVX2()
+)
+
+func block(z, t, a, b *[128]uint64) {
+	switch {
+	case useAVX2:
+		blockAVX2(z, t, a, b)
+	case useSSE4:
+		blockSSE4(z, t, a, b)
+	default:
+		blockGeneric(z, t, a, b)
+	}
+}
+
+// blockSSE4 and blockAVX2 are implemented in block_amd64.s.
+// Like blockGeneric, they compute z ^= a^b ^ P(a^b), using t as scratch space.
+
+//go:noescape
+func blockSSE4(z, t, a, b *[128]uint64)
+
+//go:noescape
+func blockAVX2(z, t, a, b *[128]uint64)
+
+// cpuid and xgetbv are implemented in cpu_amd64.s.
+func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+func xgetbv() (eax, edx uint32)
+
+func hasSSE4() bool {
+	maxID, _, _, _ := cpuid(0, 0)
+	if maxID < 1 {
+		return false
+	}
+	_, _, ecx, _ := cpuid(1, 0)
+	return ecx&(1<<19) != 0 // SSE4.1
+}
+
+func hasAVX2() bool {
+	maxID, _, _, _ := cpuid(0, 0)
+	if maxID < 7 {
+		return false
+	}
+	// The OS must save the YMM registers on context switches
+	_, _, ecx, _ := cpuid(1, 0)
+	const osxsave, avx = 1 << 27, 1 << 28
+	if ecx&osxsave == 0 || ecx&avx == 0 {
+		return false
+	}
+	if xcr0, _ := xgetbv(); xcr0&6 != 6 {
+		return false
+	}
+	_, ebx, _, _ := cpuid(7, 0)
+	return ebx&(1<<5) != 0 // AVX2
+}
@@ -0,0 +1,266 @@
+//go:build amd64 && !purego
+// +build amd64,!purego
+
+#include "textflag.h"
+
+// PSHUFB masks which rotate each quadword right by 24 and 16 bits
+DATA ·rot24<>+0x00(SB)/8, $0x0201000706050403
+DATA ·rot24<>+0x08(SB)/8, $0x0a09080f0e0d0c0b
+GLOBL ·rot24<>(SB), (NOPTR+RODATA), $16
+
+DATA ·rot16<>+0x00(SB)/8, $0x0100070605040302
+DATA ·rot16<>+0x08(SB)/8, $0x09080f0e0d0c0b0a
+GLOBL ·rot16<>(SB), (NOPTR+RODATA), $16
+
+// The permutation P operates on a 4x4 matrix of quadwords
+// with rows A, B, C, and D.
+//
+// The SSE version keeps each row in two registers:
+// A in X0 and X1, B in X2 and X3, C in X4 and X5, and D in X6 and X7.
+// It uses X8 and X9 as scratch space,
+// and expects the rotation masks in X14 and X15.
+
+// G_SSE applies the BlaMka G function to each quadword of A, B, C, and D.
+// The two halves of each row are interleaved to hide latency.
+#define G_SSE \
+	MOVO  X0, X8; MOVO X1, X9; PMULULQ X2, X8; PMULULQ X3, X9;   \
+	PADDQ X2, X0; PADDQ X3, X1; PADDQ X8, X8; PADDQ X9, X9;      \
+	PADDQ X8, X0; PADDQ X9, X1;                                  \
+	PXOR  X0, X6; PXOR X1, X7;                                   \
+	PSHUFD $0xB1, X6, X6; PSHUFD $0xB1, X7, X7;                  \
+	MOVO  X4, X8; MOVO X5, X9; PMULULQ X6, X8; PMULULQ X7, X9;   \
+	PADDQ X6, X4; PADDQ X7, X5; PADDQ X8, X8; PADDQ X9, X9;      \
+	PADDQ X8, X4; PADDQ X9, X5;                                  \
+	PXOR  X4, X2; PXOR X5, X3;                                   \
+	PSHUFB X14, X2; PSHUFB X14, X3;                              \
+	MOVO  X0, X8; MOVO X1, X9; PMULULQ X2, X8; PMULULQ X3, X9;   \
+	PADDQ X2, X0; PADDQ X3, X1; PADDQ X8, X8; PADDQ X9, X9;      \
+	PADDQ X8, X0; PADDQ X9, X1;                                  \
+	PXOR  X0, X6; PXOR X1, X7;                                   \
+	PSHUFB X15, X6; PSHUFB X15, X7;                              \
+	MOVO  X4, X8; MOVO X5, X9; PMULULQ X6, X8; PMULULQ X7, X9;   \
+	PADDQ X6, X4; PADDQ X7, X5; PADDQ X8, X8; PADDQ X9, X9;      \
+	PADDQ X8, X4; PADDQ X9, X5;                                  \
+	PXOR  X4, X2; PXOR X5, X3;                                   \
+	MOVO  X2, X8; MOVO X3, X9; PSRLQ $63, X8; PSRLQ $63, X9;     \
+	PADDQ X2, X2; PADDQ X3, X3; PXOR X8, X2; PXOR X9, X3
+
+// DIAG_SSE rotates B left by one quadword, C by two, and D by three,
+// so that the diagonals line up in columns.
+#define DIAG_SSE \
+	MOVO X3, X8; PALIGNR $8, X2, X8; PALIGNR $8, X3, X2; MOVO X2, X3; MOVO X8, X2; \
+	MOVO X4, X8; MOVO X5, X4; MOVO X8, X5;                                         \
+	MOVO X6, X8; PALIGNR $8, X7, X8; PALIGNR $8, X6, X7; MOVO X8, X6
+
+// UNDIAG_SSE undoes DIAG_SSE.
+#define UNDIAG_SSE \
+	MOVO X2, X8; PALIGNR $8, X3, X8; PALIGNR $8, X2, X3; MOVO X8, X2; \
+	MOVO X4, X8; MOVO X5, X4; MOVO X8, X5;                            \
+	MOVO X7, X8; PALIGNR $8, X6, X8; PALIGNR $8, X7, X6; MOVO X6, X7; MOVO X8, X6
+
+#define P_SSE \
+	G_SSE; DIAG_SSE; G_SSE; UNDIAG_SSE
+
+// LOAD_SSE and STORE_SSE move the 16 quadwords that P operates on
+// between memory and registers.
+// Each pair of quadwords is stride bytes after the previous one.
+#define LOAD_SSE(r, stride) \
+	MOVOU (0*stride)(r), X0; MOVOU (1*stride)(r), X1; \
+	MOVOU (2*stride)(r), X2; MOVOU (3*stride)(r), X3; \
+	MOVOU (4*stride)(r), X4; MOVOU (5*stride)(r), X5; \
+	MOVOU (6*stride)(r), X6; MOVOU (7*stride)(r), X7
+
+#define STORE_SSE(r, stride) \
+	MOVOU X0, (0*stride)(r); MOVOU X1, (1*stride)(r); \
+	MOVOU X2, (2*stride)(r); MOVOU X3, (3*stride)(r); \
+	MOVOU X4, (4*stride)(r); MOVOU X5, (5*stride)(r); \
+	MOVOU X6, (6*stride)(r); MOVOU X7, (7*stride)(r)
+
+// func blockSSE4(z, t, a, b *[128]uint64)
+TEXT ·blockSSE4(SB), NOSPLIT, $0-32
+	MOVQ z+0(FP), DX
+	MOVQ t+8(FP), DI
+	MOVQ a+16(FP), SI
+	MOVQ b+24(FP), BX
+
+	MOVOU ·rot24<>(SB), X14
+	MOVOU ·rot16<>(SB), X15
+
+	// t = a ^ b
+	XORQ CX, CX
+
+sseXor:
+	MOVOU (SI)(CX*1), X0
+	MOVOU (BX)(CX*1), X1
+	PXOR  X1, X0
+	MOVOU X0, (DI)(CX*1)
+	ADDQ  $16, CX
+	CMPQ  CX, $1024
+	JB    sseXor
+
+	// Apply P to each row of t
+	MOVQ DI, AX
+	MOVQ $8, CX
+
+sseRows:
+	LOAD_SSE(AX, 16)
+	P_SSE
+	STORE_SSE(AX, 16)
+	ADDQ $128, AX
+	DECQ CX
+	JNZ  sseRows
+
+	// and then to each column
+	MOVQ DI, AX
+	MOVQ $8, CX
+
+sseColumns:
+	LOAD_SSE(AX, 128)
+	P_SSE
+	STORE_SSE(AX, 128)
+	ADDQ $16, AX
+	DECQ CX
+	JNZ  sseColumns
+
+	// z ^= a ^ b ^ t
+	XORQ CX, CX
+
+sseOut:
+	MOVOU (SI)(CX*1), X0
+	MOVOU (BX)(CX*1), X1
+	PXOR  X1, X0
+	MOVOU (DI)(CX*1), X1
+	PXOR  X1, X0
+	MOVOU (DX)(CX*1), X1
+	PXOR  X1, X0
+	MOVOU X0, (DX)(CX*1)
+	ADDQ  $16, CX
+	CMPQ  CX, $1024
+	JB    sseOut
+
+	RET
+
+// The AVX2 version keeps each row in one register,
+// and applies P to two sets of rows at once:
+// A, B, C, and D in Y0 to Y3, and in Y4 to Y7.
+// It uses Y8 and Y9 as scratch space,
+// and expects the rotation masks in Y14 and Y15.
+
+// G_AVX applies the BlaMka G function to each quadword of both sets of rows,
+// interleaved to hide latency.
+#define G_AVX \
+	VPMULUDQ Y1, Y0, Y8; VPMULUDQ Y5, Y4, Y9; VPADDQ Y1, Y0, Y0; VPADDQ Y5, Y4, Y4; \
+	VPADDQ   Y8, Y8, Y8; VPADDQ Y9, Y9, Y9; VPADDQ Y8, Y0, Y0; VPADDQ Y9, Y4, Y4;   \
+	VPXOR    Y0, Y3, Y3; VPXOR Y4, Y7, Y7;                                         \
+	VPSHUFD  $0xB1, Y3, Y3; VPSHUFD $0xB1, Y7, Y7;                                 \
+	VPMULUDQ Y3, Y2, Y8; VPMULUDQ Y7, Y6, Y9; VPADDQ Y3, Y2, Y2; VPADDQ Y7, Y6, Y6; \
+	VPADDQ   Y8, Y8, Y8; VPADDQ Y9, Y9, Y9; VPADDQ Y8, Y2, Y2; VPADDQ Y9, Y6, Y6;   \
+	VPXOR    Y2, Y1, Y1; VPXOR Y6, Y5, Y5;                                         \
+	VPSHUFB  Y14, Y1, Y1; VPSHUFB Y14, Y5, Y5;                                     \
+	VPMULUDQ Y1, Y0, Y8; VPMULUDQ Y5, Y4, Y9; VPADDQ Y1, Y0, Y0; VPADDQ Y5, Y4, Y4; \
+	VPADDQ   Y8, Y8, Y8; VPADDQ Y9, Y9, Y9; VPADDQ Y8, Y0, Y0; VPADDQ Y9, Y4, Y4;   \
+	VPXOR    Y0, Y3, Y3; VPXOR Y4, Y7, Y7;                                         \
+	VPSHUFB  Y15, Y3, Y3; VPSHUFB Y15, Y7, Y7;                                     \
+	VPMULUDQ Y3, Y2, Y8; VPMULUDQ Y7, Y6, Y9; VPADDQ Y3, Y2, Y2; VPADDQ Y7, Y6, Y6; \
+	VPADDQ   Y8, Y8, Y8; VPADDQ Y9, Y9, Y9; VPADDQ Y8, Y2, Y2; VPADDQ Y9, Y6, Y6;   \
+	VPXOR    Y2, Y1, Y1; VPXOR Y6, Y5, Y5;                                         \
+	VPSRLQ   $63, Y1, Y8; VPSRLQ $63, Y5, Y9; VPADDQ Y1, Y1, Y1; VPADDQ Y5, Y5, Y5; \
+	VPXOR    Y8, Y1, Y1; VPXOR Y9, Y5, Y5
+
+#define DIAG_AVX(b, c, d) \
+	VPERMQ $0x39, b, b; VPERMQ $0x4E, c, c; VPERMQ $0x93, d, d
+
+#define UNDIAG_AVX(b, c, d) \
+	VPERMQ $0x93, b, b; VPERMQ $0x4E, c, c; VPERMQ $0x39, d, d
+
+#define P_AVX \
+	G_AVX;                                      \
+	DIAG_AVX(Y1, Y2, Y3); DIAG_AVX(Y5, Y6, Y7); \
+	G_AVX;                                      \
+	UNDIAG_AVX(Y1, Y2, Y3); UNDIAG_AVX(Y5, Y6, Y7)
+
+// Each row of the matrix for a row of t is contiguous.
+#define LOAD_ROWS_AVX(r) \
+	VMOVDQU 0(r), Y0; VMOVDQU 32(r), Y1; VMOVDQU 64(r), Y2; VMOVDQU 96(r), Y3; \
+	VMOVDQU 128(r), Y4; VMOVDQU 160(r), Y5; VMOVDQU 192(r), Y6; VMOVDQU 224(r), Y7
+
+#define STORE_ROWS_AVX(r) \
+	VMOVDQU Y0, 0(r); VMOVDQU Y1, 32(r); VMOVDQU Y2, 64(r); VMOVDQU Y3, 96(r); \
+	VMOVDQU Y4, 128(r); VMOVDQU Y5, 160(r); VMOVDQU Y6, 192(r); VMOVDQU Y7, 224(r)
+
+// Each row of the matrix for a column of t is made of two pairs of quadwords,
+// one row of t apart.
+#define LOAD_COLUMN_AVX(r, off, a, b, c, d, xa, xb, xc, xd) \
+	VMOVDQU (off+0)(r), xa; VINSERTI128 $1, (off+128)(r), a, a; \
+	VMOVDQU (off+256)(r), xb; VINSERTI128 $1, (off+384)(r), b, b; \
+	VMOVDQU (off+512)(r), xc; VINSERTI128 $1, (off+640)(r), c, c; \
+	VMOVDQU (off+768)(r), xd; VINSERTI128 $1, (off+896)(r), d, d
+
+#define STORE_COLUMN_AVX(r, off, a, b, c, d, xa, xb, xc, xd) \
+	VMOVDQU xa, (off+0)(r); VEXTRACTI128 $1, a, (off+128)(r); \
+	VMOVDQU xb, (off+256)(r); VEXTRACTI128 $1, b, (off+384)(r); \
+	VMOVDQU xc, (off+512)(r); VEXTRACTI128 $1, c, (off+640)(r); \
+	VMOVDQU xd, (off+768)(r); VEXTRACTI128 $1, d, (off+896)(r)
+
+// func blockAVX2(z, t, a, b *[128]uint64)
+TEXT ·blockAVX2(SB), NOSPLIT, $0-32
+	MOVQ z+0(FP), DX
+	MOVQ t+8(FP), DI
+	MOVQ a+16(FP), SI
+	MOVQ b+24(FP), BX
+
+	VBROADCASTI128 ·rot24<>(SB), Y14
+	VBROADCASTI128 ·rot16<>(SB), Y15
+
+	// t = a ^ b
+	XORQ CX, CX
+
+avxXor:
+	VMOVDQU (SI)(CX*1), Y0
+	VPXOR   (BX)(CX*1), Y0, Y0
+	VMOVDQU Y0, (DI)(CX*1)
+	ADDQ    $32, CX
+	CMPQ    CX, $1024
+	JB      avxXor
+
+	// Apply P to each row of t, two at a time
+	MOVQ DI, AX
+	MOVQ $4, CX
+
+avxRows:
+	LOAD_ROWS_AVX(AX)
+	P_AVX
+	STORE_ROWS_AVX(AX)
+	ADDQ $256, AX
+	DECQ CX
+	JNZ  avxRows
+
+	// and then to each column, two at a time
+	MOVQ DI, AX
+	MOVQ $4, CX
+
+avxColumns:
+	LOAD_COLUMN_AVX(AX, 0, Y0, Y1, Y2, Y3, X0, X1, X2, X3)
+	LOAD_COLUMN_AVX(AX, 16, Y4, Y5, Y6, Y7, X4, X5, X6, X7)
+	P_AVX
+	STORE_COLUMN_AVX(AX, 0, Y0, Y1, Y2, Y3, X0, X1, X2, X3)
+	STORE_COLUMN_AVX(AX, 16, Y4, Y5, Y6, Y7, X4, X5, X6, X7)
+	ADDQ $32, AX
+	DECQ CX
+	JNZ  avxColumns
+
+	// z ^= a ^ b ^ t
+	XORQ CX, CX
+
+avxOut:
+	VMOVDQU (SI)(CX*1), Y0
+	VPXOR   (BX)(CX*1), Y0, Y0
+	VPXOR   (DI)(CX*1), Y0, Y0
+	VPXOR   (DX)(CX*1), Y0, Y0
+	VMOVDQU Y0, (DX)(CX*1)
+	ADDQ    $32, CX
+	CMPQ    CX, $1024
+	JB      avxOut
+
+	VZEROUPPER
+	RET
@@ -0,0 +1,117 @@
+//go:build amd64 && !purego && go1.18
+// +build amd64,!purego,go1.18
+
+package argon2
+
+import (
+	"math/rand"
+	"testing"
+)
+
+type blockImpl struct {
+	name  string
+	ok    bool
+	block func(z, t, a, b *[128]uint64)
+}
+
+var blockImpls = []blockImpl{
+	{"SSE4", useSSE4, blockSSE4},
+	{"AVX2", useAVX2, blockAVX2},
+}
+
+// withImpl runs f with block using only the given implementation.
+func withImpl(impl string, f func()) {
+	sse4, avx2 := useSSE4, useAVX2
+	defer func() { useSSE4, useAVX2 = sse4, avx2 }()
+	useSSE4 = impl == "SSE4"
+	useAVX2 = impl == "AVX2"
+	f()
+}
+
+// Runs the test vectors with each implementation of block
+func TestBlockImpls(t *testing.T) {
+	for _, impl := range append([]blockImpl{{name: "Generic", ok: true}}, blockImpls...) {
+		t.Run(impl.name, func(t *testing.T) {
+			if !impl.ok {
+				t.Skipf("%s is not supported", impl.name)
+			}
+			withImpl(impl.name, func() {
+				TestArgon_Vector(t)
+				TestArgon(t)
+			})
+		})
+	}
+}
+
+func checkBlock(t *testing.T, z, a, b *[128]uint64) {
+	t.Helper()
+	var want, tmp [128]uint64
+	want = *z
+	blockGeneric(&want, &tmp, a, b)
+	for _, impl := range blockImpls {
+		if !impl.ok {
+			continue
+		}
+		got := *z
+		impl.block(&got, &tmp, a, b)
+		if got != want {
+			t.Fatalf("%s: got %x, want %x", impl.name, got, want)
+		}
+	}
+}
+
+func TestBlock(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	var z, a, b [128]uint64
+	for i := 0; i < 1000; i++ {
+		for j := range z {
+			z[j], a[j], b[j] = r.Uint64(), r.Uint64(), r.Uint64()
+		}
+		checkBlock(t, &z, &a, &b)
+	}
+
+	// All ones, to exercise the carries
+	for j := range z {
+		z[j], a[j], b[j] = 0, ^uint64(0), 0
+	}
+	checkBlock(t, &z, &a, &b)
+}
+
+func FuzzBlock(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{0xff})
+	f.Add(repeat(0x5a, 3*1024))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// The data fills z, a, and b in turn; the rest is zero
+		var blocks [3][128]uint64
+		for i := 0; i+8 <= len(data) && i < 3*1024; i += 8 {
+			blocks[i/1024][i%1024/8] = read64(data[i:])
+		}
+		checkBlock(t, &blocks[0], &blocks[1], &blocks[2])
+	})
+}
+
+func BenchmarkBlock(b *testing.B) {
+	var z, tmp, x, y [128]uint64
+	for i := range x {
+		x[i], y[i] = uint64(i), uint64(i)*0x9e3779b97f4a7c15
+	}
+	b.Run("Generic", func(b *testing.B) {
+		b.SetBytes(1024)
+		for i := 0; i < b.N; i++ {
+			blockGeneric(&z, &tmp, &x, &y)
+		}
+	})
+	for _, impl := range blockImpls {
+		impl := impl
+		b.Run(impl.name, func(b *testing.B) {
+			if !impl.ok {
+				b.Skipf("%s is not supported", impl.name)
+			}
+			b.SetBytes(1024)
+			for i := 0; i < b.N; i++ {
+				impl.block(&z, &tmp, &x, &y)
+			}
+		})
+	}
+}
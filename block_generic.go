@@ -0,0 +1,8 @@
+//go:build !amd64 || purego
+// +build !amd64 purego
+
+package argon2
+
+func block(z, t, a, b *[128]uint64) {
+	blockGeneric(z, t, a, b)
+}
@@ -0,0 +1,139 @@
+package argon2
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// A Measurement records how long it took to derive a key
+// with a given amount of memory and number of passes.
+type Measurement struct {
+	Memory   uint32 // in kibibytes
+	Time     uint32 // number of passes
+	Duration time.Duration
+}
+
+// ErrCalibrate is returned by Calibrate when the target duration cannot be met
+// even with the minimum amount of memory and a single pass.
+var ErrCalibrate = errors.New("argon: cannot meet target duration")
+
+// Calibrate finds the strongest Argon2id parameters that derive a key
+// on the current machine in no more than the target duration,
+// using at most maxMemory kibibytes and maxThreads lanes and threads.
+//
+// Calibrate follows the procedure in section 4 of RFC 9106:
+// it first finds the largest amount of memory, up to maxMemory,
+// for which a single pass fits in the target duration,
+// and then the largest number of passes with that much memory.
+//
+// The measurements taken along the way are returned as well,
+// in the order they were taken, so that the choice can be explained.
+// If the target cannot be met, Calibrate returns
+// the weakest parameters it tried along with ErrCalibrate.
+func Calibrate(target time.Duration, maxMemory uint32, maxThreads int) (Params, []Measurement, error) {
+	return calibrate(target, maxMemory, maxThreads, measure)
+}
+
+// measure returns how long it takes to derive a key with the given parameters.
+func measure(p Params) time.Duration {
+	var pw, salt [16]byte
+	out := make([]byte, p.TagLength)
+	start := time.Now()
+	argon2(context.Background(), out, pw[:], salt[:], nil, nil, p.Lanes, p.Memory, p.Time, p.Variant, p.version(), p.threads(), nil, nil, nil)
+	return time.Since(start)
+}
+
+func calibrate(target time.Duration, maxMemory uint32, maxThreads int, measure func(Params) time.Duration) (Params, []Measurement, error) {
+	if maxThreads < 1 {
+		return Params{}, nil, ErrInvalidThreads
+	}
+	lanes := uint32(maxPar)
+	if maxThreads < maxPar {
+		lanes = uint32(maxThreads)
+	}
+	p := Params{
+		Variant:   Argon2id,
+		Version:   Version13,
+		Time:      1,
+		Memory:    maxMemory,
+		Lanes:     lanes,
+		Threads:   maxThreads,
+		TagLength: 32,
+	}
+	laneMin := 8 * lanes
+	if p.Memory < laneMin {
+		p.Memory = laneMin
+	}
+
+	var ms []Measurement
+	run := func(p Params) time.Duration {
+		d := measure(p)
+		ms = append(ms, Measurement{Memory: p.Memory, Time: p.Time, Duration: d})
+		return d
+	}
+
+	// Memory first: halve it until a single pass is fast enough
+	d := run(p)
+	for d > target {
+		if p.Memory <= laneMin {
+			return p, ms, ErrCalibrate
+		}
+		p.Memory /= 2
+		if p.Memory < laneMin {
+			p.Memory = laneMin
+		}
+		d = run(p)
+	}
+
+	// Then passes. The time is roughly linear in the number of passes,
+	// so extrapolate from the measurements so far.
+	// The measurements are noisy, and the first one is slowed down
+	// by page faults on the fresh matrix, so each guess is limited
+	// to twice the most passes known to fit. Since those fit in the target,
+	// no guess is predicted to take much more than twice the target.
+	d1 := d
+	if d1 <= 0 {
+		d1 = 1
+	}
+	lo, dlo := uint64(1), d1  // the most passes known to fit
+	hi := uint64(maxIter + 1) // the fewest passes known not to fit
+	var dhi time.Duration
+	for i := 0; i < 64; i++ {
+		// Estimate the cost of each pass beyond lo.
+		// If the later measurements are no slower than the first,
+		// fall back to the average, which includes the fixed costs
+		// and so errs on the side of too few passes.
+		perPass := dlo / time.Duration(lo)
+		if lo > 1 && dlo > d1 {
+			perPass = (dlo - d1) / time.Duration(lo-1)
+		}
+		if perPass <= 0 {
+			perPass = 1
+		}
+		t := lo + uint64((target-dlo)/perPass)
+		if dhi > 0 {
+			// Interpolate between lo and hi
+			t = lo + uint64(float64(hi-lo)*float64(target-dlo)/float64(dhi-dlo))
+		}
+		if t > 2*lo {
+			t = 2 * lo
+		}
+		if t >= hi {
+			t = hi - 1
+		}
+		if t <= lo {
+			break
+		}
+		q := p
+		q.Time = uint32(t)
+		if d := run(q); d <= target {
+			lo, dlo = t, d
+		} else {
+			hi, dhi = t, d
+		}
+	}
+	p.Time = uint32(lo)
+
+	return p, ms, nil
+}
@@ -0,0 +1,101 @@
+package argon2
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCalibrate(t *testing.T) {
+	// A fake machine that hashes 1 KiB per microsecond,
+	// with 50µs of overhead
+	cost := func(p Params) time.Duration {
+		return time.Duration(p.Memory)*time.Duration(p.Time)*time.Microsecond + 50*time.Microsecond
+	}
+
+	var tests = []struct {
+		target     time.Duration
+		maxMemory  uint32
+		maxThreads int
+		mem, time  uint32
+		err        error
+	}{
+		// Memory is reduced until one pass fits
+		{target: 10 * time.Millisecond, maxMemory: 1 << 16, maxThreads: 4, mem: 8192, time: 1},
+		// Passes are increased until the target is reached
+		{target: 10 * time.Millisecond, maxMemory: 1000, maxThreads: 2, mem: 1000, time: 9},
+		{target: 100 * time.Millisecond, maxMemory: 64, maxThreads: 1, mem: 64, time: 1561},
+		// Lanes need at least 8 KiB each
+		{target: 10 * time.Millisecond, maxMemory: 8, maxThreads: 4, mem: 32, time: 310},
+		{target: 10 * time.Microsecond, maxMemory: 1 << 10, maxThreads: 1, mem: 8, time: 1, err: ErrCalibrate},
+	}
+	for _, tt := range tests {
+		p, ms, err := calibrate(tt.target, tt.maxMemory, tt.maxThreads, cost)
+		if err != tt.err {
+			t.Errorf("%+v: got error %v, want %v", tt, err, tt.err)
+		}
+		if p.Memory != tt.mem || p.Time != tt.time {
+			t.Errorf("%+v: got m=%d, t=%d, want m=%d, t=%d", tt, p.Memory, p.Time, tt.mem, tt.time)
+		}
+		if err := p.Validate(); err != nil {
+			t.Errorf("%+v: %v", tt, err)
+		}
+		if len(ms) == 0 {
+			t.Errorf("%+v: no measurements", tt)
+		}
+		for _, m := range ms {
+			if m.Duration != cost(Params{Memory: m.Memory, Time: m.Time}) {
+				t.Errorf("%+v: bad measurement %+v", tt, m)
+			}
+		}
+	}
+}
+
+func TestCalibrate_Real(t *testing.T) {
+	target := 20 * time.Millisecond
+	p, ms, err := Calibrate(target, 1024, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if p.Variant != Argon2id || p.Lanes != 2 || p.Memory > 1024 {
+		t.Errorf("bad params %+v", p)
+	}
+	found := false
+	for _, m := range ms {
+		if m.Memory == p.Memory && m.Time == p.Time {
+			found = true
+			if m.Duration > target {
+				t.Errorf("chose %+v, which is over the target", m)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("chosen params %+v were not measured", p)
+	}
+}
+
+// Checks that a slow first pass, as when the matrix is first faulted in,
+// does not lead to wild guesses
+func TestCalibrate_SlowFirstPass(t *testing.T) {
+	cost := func(p Params) time.Duration {
+		if p.Time == 1 {
+			return 5900 * time.Microsecond
+		}
+		return time.Duration(p.Time) * 1800 * time.Microsecond
+	}
+	target := 20 * time.Millisecond
+	p, ms, err := calibrate(target, 1024, 2, cost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Time != 11 {
+		t.Errorf("got t=%d, want 11", p.Time)
+	}
+	for _, m := range ms {
+		if m.Duration > 2*target {
+			t.Errorf("measured %+v, which is far over the target", m)
+		}
+	}
+}
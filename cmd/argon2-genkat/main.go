@@ -0,0 +1,156 @@
+// Command argon2-genkat prints the known-answer test transcript
+// of the reference implementation's genkat tool.
+//
+// Usage:
+//
+//	argon2-genkat [d|i|id] [16|19]
+//
+// The type defaults to i and the version to 19.
+// The output is identical to the files in the kats directory
+// of the reference implementation, so the two can be compared with diff.
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/magical/argon2"
+)
+
+func main() {
+	mode := argon2.Argon2i
+	version := uint32(argon2.Version13)
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "d":
+			mode = argon2.Argon2d
+		case "i":
+			mode = argon2.Argon2i
+		case "id":
+			mode = argon2.Argon2id
+		default:
+			fatal("wrong Argon2 type")
+		}
+	}
+	if len(os.Args) > 2 {
+		v, err := strconv.ParseUint(os.Args[2], 10, 32)
+		if err != nil || v != argon2.Version10 && v != argon2.Version13 {
+			fatal("wrong Argon2 version number")
+		}
+		version = uint32(v)
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	if err := genkat(w, mode, version); err != nil {
+		fatal(err.Error())
+	}
+	if err := w.Flush(); err != nil {
+		fatal(err.Error())
+	}
+}
+
+func fatal(msg string) {
+	fmt.Fprintf(os.Stderr, "Error: %s\n", msg)
+	os.Exit(1)
+}
+
+// genkat writes the transcript for the reference test vector
+// with the given variant and version to w.
+func genkat(w io.Writer, mode argon2.Variant, version uint32) error {
+	t := &katTracer{
+		w:        w,
+		password: repeat(1, 32),
+		salt:     repeat(2, 16),
+		secret:   repeat(3, 8),
+		data:     repeat(4, 12),
+	}
+	p := argon2.Params{
+		Variant:   mode,
+		Version:   version,
+		Time:      3,
+		Memory:    32,
+		Lanes:     4,
+		TagLength: 32,
+		Tracer:    t,
+	}
+	tag, err := p.KeyWithSecret(t.password, t.salt, t.secret, t.data)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Tag: ")
+	printBytes(w, tag)
+	return nil
+}
+
+// katTracer prints events in the format of the reference implementation.
+type katTracer struct {
+	w                            io.Writer
+	password, salt, secret, data []byte
+}
+
+func (t *katTracer) Trace(e argon2.Event) {
+	w := t.w
+	switch e := e.(type) {
+	case argon2.InputHashEvent:
+		fmt.Fprintf(w, "=======================================\n")
+		fmt.Fprintf(w, "%s version number %d\n", typeName(e.Variant), e.Version)
+		fmt.Fprintf(w, "=======================================\n")
+		fmt.Fprintf(w, "Memory: %d KiB, Iterations: %d, Parallelism: %d lanes, Tag length: %d bytes\n",
+			e.Memory, e.Time, e.Lanes, e.TagLength)
+		fmt.Fprintf(w, "Password[%d]: ", len(t.password))
+		printBytes(w, t.password)
+		fmt.Fprintf(w, "Salt[%d]: ", len(t.salt))
+		printBytes(w, t.salt)
+		fmt.Fprintf(w, "Secret[%d]: ", len(t.secret))
+		printBytes(w, t.secret)
+		fmt.Fprintf(w, "Associated data[%d]: ", len(t.data))
+		printBytes(w, t.data)
+		fmt.Fprintf(w, "Pre-hashing digest: ")
+		printBytes(w, e.Hash)
+	case argon2.PassEvent:
+		fmt.Fprintf(w, "\n After pass %d:\n", e.Pass)
+		// Only the first word of each block is printed
+		// if there are more blocks than words in a block
+		words := 128
+		if len(e.Blocks) > 128 {
+			words = 1
+		}
+		for i := range e.Blocks {
+			for j, v := range e.Blocks[i][:words] {
+				fmt.Fprintf(w, "Block %.4d [%3d]: %016x\n", i, j, v)
+			}
+		}
+	}
+}
+
+// typeName returns the capitalized name of the variant.
+func typeName(v argon2.Variant) string {
+	switch v {
+	case argon2.Argon2d:
+		return "Argon2d"
+	case argon2.Argon2i:
+		return "Argon2i"
+	case argon2.Argon2id:
+		return "Argon2id"
+	}
+	return v.String()
+}
+
+// printBytes prints each byte in hex followed by a space, and then a newline.
+func printBytes(w io.Writer, b []byte) {
+	for _, c := range b {
+		fmt.Fprintf(w, "%02x ", c)
+	}
+	fmt.Fprintf(w, "\n")
+}
+
+func repeat(v byte, n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = v
+	}
+	return b
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/magical/argon2"
+)
+
+// Checks the output against the checksums in the kats directory
+// of the reference implementation.
+func TestGenkat(t *testing.T) {
+	var tests = []struct {
+		file    string
+		mode    argon2.Variant
+		version uint32
+		sum     string
+	}{
+		{"argon2d", argon2.Argon2d, argon2.Version13, "73619cfe0f35e52fdd1ca2595ffaa359879467407f98b61f4969c2861cc329ce"},
+		{"argon2d_v16", argon2.Argon2d, argon2.Version10, "4ec4569a016c3accc6a25a34252b03a6135939b3c452389917a3f3b65878165b"},
+		{"argon2i", argon2.Argon2i, argon2.Version13, "40a3aeafb092d10cf457a8ee0139c114c911ecf97bd5accf5a99c7ddd6917061"},
+		{"argon2i_v16", argon2.Argon2i, argon2.Version10, "334f03e627afb67b946a530b90d2e11fb2e6abb44df992c0fb3198c7bacf5930"},
+		{"argon2id", argon2.Argon2id, argon2.Version13, "ba05643e504fc5778dda99e2d9f42ebe7d22ebb3923cc719fd591b1b14a8d28d"},
+		{"argon2id_v16", argon2.Argon2id, argon2.Version10, "680774be1d3ad2e74bbc56ee715dd6eb97a58279bf22edc57d00e840ca1ae469"},
+	}
+	for _, tt := range tests {
+		h := sha256.New()
+		if err := genkat(h, tt.mode, tt.version); err != nil {
+			t.Errorf("%s: %v", tt.file, err)
+			continue
+		}
+		if got := hex.EncodeToString(h.Sum(nil)); got != tt.sum {
+			t.Errorf("%s: got sha256 %s, want %s", tt.file, got, tt.sum)
+		}
+	}
+}
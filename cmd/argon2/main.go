@@ -0,0 +1,345 @@
+// Command argon2 hashes a password read from standard input.
+//
+// It is a drop-in replacement for the argon2 command
+// from the reference implementation, and accepts the same arguments:
+//
+//	argon2 [-h] salt [-i|-d|-id] [-t iterations] [-m log2(memory in KiB) | -k memory in KiB] [-p parallelism] [-l hash length] [-e|-r] [-v (10|13)]
+//
+// The output and exit status are also the same,
+// except that at most 255 lanes are supported
+// and the reported time is wall-clock time rather than CPU time.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/magical/argon2"
+)
+
+const (
+	defaultTime    = 3
+	defaultLogMem  = 12 // 2^12 KiB = 4 MiB
+	defaultLanes   = 1
+	defaultOutLen  = 32
+	maxPasswordLen = 128
+	maxMemoryBits  = 32
+	maxLanes       = 255
+
+	// The reference implementation returns ARGON2_MISSING_ARGS (-30)
+	// from main, which becomes 226.
+	exitMissingArgs = 256 - 30
+)
+
+func main() {
+	os.Exit(run(os.Args, os.Stdin, os.Stdout, os.Stderr))
+}
+
+type cmd struct {
+	name           string
+	stdout, stderr io.Writer
+}
+
+func (c *cmd) usage() {
+	w := c.stdout
+	fmt.Fprintf(w, "Usage:  %s [-h] salt [-i|-d|-id] [-t iterations] [-m log2(memory in KiB) | -k memory in KiB] [-p parallelism] [-l hash length] [-e|-r] [-v (10|13)]\n", c.name)
+	fmt.Fprintf(w, "\tPassword is read from stdin\n")
+	fmt.Fprintf(w, "Parameters:\n")
+	fmt.Fprintf(w, "\tsalt\t\tThe salt to use, at least 8 characters\n")
+	fmt.Fprintf(w, "\t-i\t\tUse Argon2i (this is the default)\n")
+	fmt.Fprintf(w, "\t-d\t\tUse Argon2d instead of Argon2i\n")
+	fmt.Fprintf(w, "\t-id\t\tUse Argon2id instead of Argon2i\n")
+	fmt.Fprintf(w, "\t-t N\t\tSets the number of iterations to N (default = %d)\n", defaultTime)
+	fmt.Fprintf(w, "\t-m N\t\tSets the memory usage of 2^N KiB (default %d)\n", defaultLogMem)
+	fmt.Fprintf(w, "\t-k N\t\tSets the memory usage of N KiB (default %d)\n", 1<<defaultLogMem)
+	fmt.Fprintf(w, "\t-p N\t\tSets parallelism to N threads (default %d)\n", defaultLanes)
+	fmt.Fprintf(w, "\t-l N\t\tSets hash output length to N bytes (default %d)\n", defaultOutLen)
+	fmt.Fprintf(w, "\t-e\t\tOutput only encoded hash\n")
+	fmt.Fprintf(w, "\t-r\t\tOutput only the raw bytes of the hash\n")
+	fmt.Fprintf(w, "\t-v (10|13)\tArgon2 version (defaults to the most recent version, currently %x)\n", argon2.Version13)
+	fmt.Fprintf(w, "\t-h\t\tPrint %s usage\n", c.name)
+}
+
+// fatal prints an error message and returns the exit status.
+func (c *cmd) fatal(msg string) int {
+	fmt.Fprintf(c.stderr, "Error: %s\n", msg)
+	return 1
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	c := &cmd{name: args[0], stdout: stdout, stderr: stderr}
+	if len(args) < 2 {
+		c.usage()
+		return exitMissingArgs
+	} else if args[1] == "-h" {
+		c.usage()
+		return 1
+	}
+
+	pw := make([]byte, maxPasswordLen)
+	n, _ := io.ReadFull(stdin, pw)
+	if n < 1 {
+		return c.fatal("no password read")
+	}
+	if n == maxPasswordLen {
+		return c.fatal("Provided password longer than supported in command line utility")
+	}
+	pw = pw[:n]
+	salt := args[1]
+
+	p := argon2.Params{
+		Variant:   argon2.Argon2i,
+		Version:   argon2.Version13,
+		Time:      defaultTime,
+		Memory:    1 << defaultLogMem,
+		Lanes:     defaultLanes,
+		TagLength: defaultOutLen,
+	}
+	var types int
+	var memorySet, encodedOnly, rawOnly bool
+	for i := 2; i < len(args); i++ {
+		a := args[i]
+		// next returns the option's argument, if there is one
+		next := func() (string, bool) {
+			if i < len(args)-1 {
+				i++
+				return args[i], true
+			}
+			return "", false
+		}
+		switch a {
+		case "-h":
+			c.usage()
+			return 1
+		case "-m":
+			if memorySet {
+				return c.fatal("-m or -k can only be used once")
+			}
+			memorySet = true
+			s, ok := next()
+			if !ok {
+				return c.fatal("missing -m argument")
+			}
+			v := strtoul(s)
+			if v == 0 || v == maxUlong || v > maxMemoryBits {
+				return c.fatal("bad numeric input for -m")
+			}
+			p.Memory = uint32(min(1<<v, 1<<32-1))
+		case "-k":
+			if memorySet {
+				return c.fatal("-m or -k can only be used once")
+			}
+			memorySet = true
+			s, ok := next()
+			if !ok {
+				return c.fatal("missing -k argument")
+			}
+			v := strtoul(s)
+			if v == 0 || v == maxUlong {
+				return c.fatal("bad numeric input for -k")
+			}
+			p.Memory = uint32(min(v, 1<<32-1))
+		case "-t":
+			s, ok := next()
+			if !ok {
+				return c.fatal("missing -t argument")
+			}
+			v := strtoul(s)
+			if v == 0 || v == maxUlong || v > 1<<32-1 {
+				return c.fatal("bad numeric input for -t")
+			}
+			p.Time = uint32(v)
+		case "-p":
+			s, ok := next()
+			if !ok {
+				return c.fatal("missing -p argument")
+			}
+			v := strtoul(s)
+			if v == 0 || v == maxUlong || v > 0xFFFFFF {
+				return c.fatal("bad numeric input for -p")
+			}
+			if v > maxLanes {
+				return c.fatal("Too many lanes")
+			}
+			p.Lanes = uint32(v)
+		case "-l":
+			s, ok := next()
+			if !ok {
+				return c.fatal("missing -l argument")
+			}
+			v := strtoul(s)
+			if v == maxUlong || v > 1<<32-1 {
+				return c.fatal("bad numeric input for -l")
+			}
+			p.TagLength = uint32(v)
+		case "-i":
+			p.Variant = argon2.Argon2i
+			types++
+		case "-d":
+			p.Variant = argon2.Argon2d
+			types++
+		case "-id":
+			p.Variant = argon2.Argon2id
+			types++
+		case "-e":
+			encodedOnly = true
+		case "-r":
+			rawOnly = true
+		case "-v":
+			s, ok := next()
+			if !ok {
+				return c.fatal("missing -v argument")
+			}
+			switch s {
+			case "10":
+				p.Version = argon2.Version10
+			case "13":
+				p.Version = argon2.Version13
+			default:
+				return c.fatal("invalid Argon2 version")
+			}
+		default:
+			return c.fatal("unknown argument")
+		}
+	}
+	if types > 1 {
+		return c.fatal("cannot specify multiple Argon2 types")
+	}
+	if encodedOnly && rawOnly {
+		return c.fatal("cannot provide both -e and -r")
+	}
+	p.Threads = int(p.Lanes)
+
+	if !encodedOnly && !rawOnly {
+		fmt.Fprintf(stdout, "Type:\t\t%s\n", typeName(p.Variant))
+		fmt.Fprintf(stdout, "Iterations:\t%d\n", p.Time)
+		fmt.Fprintf(stdout, "Memory:\t\t%d KiB\n", p.Memory)
+		fmt.Fprintf(stdout, "Parallelism:\t%d\n", p.Lanes)
+	}
+
+	start := time.Now()
+	// Check the inputs in the same order as the reference implementation.
+	// It also rejects less than 8 KiB per lane instead of rounding up.
+	switch {
+	case p.TagLength < 4:
+		return c.fatal("Output is too short")
+	case len(salt) < 8:
+		return c.fatal("Salt is too short")
+	case p.Memory < 8 || uint64(p.Memory) < 8*uint64(p.Lanes):
+		return c.fatal("Memory cost is too small")
+	}
+	key, err := p.Key(pw, []byte(salt))
+	if err != nil {
+		return c.fatal(errorMessage(err))
+	}
+	elapsed := time.Since(start)
+	h := argon2.Hash{
+		Variant: p.Variant,
+		Version: p.Version,
+		Memory:  p.Memory,
+		Time:    p.Time,
+		Lanes:   p.Lanes,
+		Salt:    []byte(salt),
+		Key:     key,
+	}
+	encoded := h.String()
+
+	if encodedOnly {
+		fmt.Fprintln(stdout, encoded)
+	}
+	if rawOnly {
+		fmt.Fprintf(stdout, "%x\n", key)
+	}
+	if encodedOnly || rawOnly {
+		return 0
+	}
+	fmt.Fprintf(stdout, "Hash:\t\t%x\n", key)
+	fmt.Fprintf(stdout, "Encoded:\t%s\n", encoded)
+	fmt.Fprintf(stdout, "%2.3f seconds\n", elapsed.Seconds())
+	if err := argon2.Verify(encoded, pw); err != nil {
+		return c.fatal(errorMessage(err))
+	}
+	fmt.Fprintf(stdout, "Verification ok\n")
+	return 0
+}
+
+// errorMessage returns the reference implementation's message for err.
+func errorMessage(err error) string {
+	switch {
+	case err == argon2.ErrInvalidTagLength:
+		return "Output is too short"
+	case err == argon2.ErrSaltTooShort:
+		return "Salt is too short"
+	case err == argon2.ErrSaltTooLong:
+		return "Salt is too long"
+	case err == argon2.ErrPasswordTooLong:
+		return "Password is too long"
+	case err == argon2.ErrInvalidMemory:
+		return "Memory cost is too small"
+	case err == argon2.ErrInvalidTime:
+		return "Time cost is too small"
+	case err == argon2.ErrInvalidLanes:
+		return "Too many lanes"
+	case err == argon2.ErrInvalidVersion:
+		return "There is no such version of Argon2"
+	case err == argon2.ErrMismatch:
+		return "The password does not match the supplied hash"
+	case errors.Is(err, argon2.ErrInvalidHash):
+		return "Decoding failed"
+	}
+	return err.Error()
+}
+
+// typeName returns the capitalized name of the variant.
+func typeName(v argon2.Variant) string {
+	switch v {
+	case argon2.Argon2d:
+		return "Argon2d"
+	case argon2.Argon2i:
+		return "Argon2i"
+	case argon2.Argon2id:
+		return "Argon2id"
+	}
+	return v.String()
+}
+
+const maxUlong = 1<<64 - 1
+
+// strtoul parses a number like C's strtoul with base 10:
+// leading spaces and a sign are allowed, parsing stops at the first
+// non-digit, a negative number is negated modulo 2^64,
+// and numbers which are too large become maxUlong.
+func strtoul(s string) uint64 {
+	s = strings.TrimLeft(s, " \t\n\v\f\r")
+	neg := false
+	if s != "" && (s[0] == '+' || s[0] == '-') {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+	var v uint64
+	for _, c := range []byte(s) {
+		if c < '0' || c > '9' {
+			break
+		}
+		d := uint64(c - '0')
+		if v > (maxUlong-d)/10 {
+			return maxUlong
+		}
+		v = v*10 + d
+	}
+	if neg {
+		v = -v
+	}
+	return v
+}
+
+func min(a, b uint64) uint64 {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var secondsRE = regexp.MustCompile(`(?m)^[0-9.]+ seconds$`)
+
+func TestRun(t *testing.T) {
+	var tests = []struct {
+		args   string
+		stdin  string
+		code   int
+		stdout string
+		stderr string
+	}{
+		// From the reference implementation's README
+		{
+			args:  "somesalt -t 2 -m 16 -p 4 -l 24",
+			stdin: "password",
+			stdout: "Type:\t\tArgon2i\n" +
+				"Iterations:\t2\n" +
+				"Memory:\t\t65536 KiB\n" +
+				"Parallelism:\t4\n" +
+				"Hash:\t\t45d7ac72e76f242b20b77b9bf9bf9d5915894e669a24e6c6\n" +
+				"Encoded:\t$argon2i$v=19$m=65536,t=2,p=4$c29tZXNhbHQ$RdescudvJCsgt3ub+b+dWRWJTmaaJObG\n" +
+				"X seconds\n" +
+				"Verification ok\n",
+		},
+		{
+			args:   "somesalt -id -t 3 -m 10 -p 4 -l 32 -e",
+			stdin:  "password",
+			stdout: "$argon2id$v=19$m=1024,t=3,p=4$c29tZXNhbHQ$48P/BUfzHAN7My91Of4w9r6Kq8TPdl0Q6zSEeo8/pDw\n",
+		},
+		{
+			args:   "somesalt -k 100 -r",
+			stdin:  "password",
+			stdout: "e745e57ac052b947c341697296b70879afe4f28b2a118234ee7a293ff3265cea\n",
+		},
+		{
+			args:   "somesalt -d -v 10 -t 1 -k 8 -e",
+			stdin:  "password",
+			stdout: "$argon2d$v=16$m=8,t=1,p=1$c29tZXNhbHQ$CiOiJhumPbpR6E5hGmsCYgF+3/fhd0KAUqgMpAXZFdc\n",
+		},
+
+		{args: "", code: exitMissingArgs, stdout: "Usage:"},
+		{args: "-h", code: 1, stdout: "Usage:"},
+		{args: "somesalt -t 1 -h", stdin: "password", code: 1, stdout: "Usage:"},
+		{args: "somesalt", code: 1, stderr: "Error: no password read\n"},
+		{args: "somesalt", stdin: strings.Repeat("x", 128), code: 1, stderr: "Error: Provided password longer than supported in command line utility\n"},
+		{args: "somesalt -x", stdin: "password", code: 1, stderr: "Error: unknown argument\n"},
+		{args: "somesalt -t", stdin: "password", code: 1, stderr: "Error: missing -t argument\n"},
+		{args: "somesalt -t 0", stdin: "password", code: 1, stderr: "Error: bad numeric input for -t\n"},
+		{args: "somesalt -t -1", stdin: "password", code: 1, stderr: "Error: bad numeric input for -t\n"},
+		{args: "somesalt -m 33", stdin: "password", code: 1, stderr: "Error: bad numeric input for -m\n"},
+		{args: "somesalt -l -1", stdin: "password", code: 1, stderr: "Error: bad numeric input for -l\n"},
+		{args: "somesalt -l 4294967300", stdin: "password", code: 1, stderr: "Error: bad numeric input for -l\n"},
+		{args: "somesalt -m 4 -k 16", stdin: "password", code: 1, stderr: "Error: -m or -k can only be used once\n"},
+		{args: "somesalt -p 256", stdin: "password", code: 1, stderr: "Error: Too many lanes\n"},
+		{args: "somesalt -v 12", stdin: "password", code: 1, stderr: "Error: invalid Argon2 version\n"},
+		{args: "somesalt -i -d", stdin: "password", code: 1, stderr: "Error: cannot specify multiple Argon2 types\n"},
+		{args: "somesalt -e -r", stdin: "password", code: 1, stderr: "Error: cannot provide both -e and -r\n"},
+		{args: "short -e", stdin: "password", code: 1, stderr: "Error: Salt is too short\n"},
+		{args: "somesalt -l 3 -e", stdin: "password", code: 1, stderr: "Error: Output is too short\n"},
+		{args: "somesalt -k 16 -p 4 -e", stdin: "password", code: 1, stderr: "Error: Memory cost is too small\n"},
+	}
+	for _, tt := range tests {
+		args := append([]string{"argon2"}, strings.Fields(tt.args)...)
+		var stdout, stderr bytes.Buffer
+		code := run(args, strings.NewReader(tt.stdin), &stdout, &stderr)
+		if code != tt.code {
+			t.Errorf("%s: got exit status %d, want %d", tt.args, code, tt.code)
+		}
+		out := secondsRE.ReplaceAllString(stdout.String(), "X seconds")
+		if tt.stdout == "Usage:" {
+			if !strings.HasPrefix(out, "Usage:") {
+				t.Errorf("%s: got stdout %q, want usage", tt.args, out)
+			}
+		} else if out != tt.stdout {
+			t.Errorf("%s: got stdout %q, want %q", tt.args, out, tt.stdout)
+		}
+		if stderr.String() != tt.stderr {
+			t.Errorf("%s: got stderr %q, want %q", tt.args, stderr.String(), tt.stderr)
+		}
+	}
+}
+
+func TestStrtoul(t *testing.T) {
+	var tests = []struct {
+		s    string
+		want uint64
+	}{
+		{"", 0},
+		{"abc", 0},
+		{"12", 12},
+		{"  +12x", 12},
+		{"-1", maxUlong},
+		{"18446744073709551615", maxUlong},
+		{"18446744073709551616", maxUlong},
+		{"18446744073709551614", maxUlong - 1},
+	}
+	for _, tt := range tests {
+		if got := strtoul(tt.s); got != tt.want {
+			t.Errorf("strtoul(%q) = %d, want %d", tt.s, got, tt.want)
+		}
+	}
+}
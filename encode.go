@@ -0,0 +1,350 @@
+package argon2
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+const (
+	maxKeyID    = 8
+	maxHashData = 32
+)
+
+// A Hash is an Argon2 hash together with the parameters used to compute it.
+//
+// Hashes are usually stored in the PHC string format,
+// which is also used by libargon2 and most other implementations:
+//
+//	$argon2id$v=19$m=65536,t=3,p=4$c29tZXNhbHQ$RdescudvJCsgt3ub+b+dWRWJTmaaJObG
+//
+// The optional keyid and data fields are also supported:
+//
+//	$argon2id$v=19$m=65536,t=3,p=4,keyid=AQID,data=BAUG$c29tZXNhbHQ$RdescudvJCsgt3ub+b+dWRWJTmaaJObG
+type Hash struct {
+	Variant Variant
+	Version uint32 // Version10, Version13, or zero, which means Version13
+
+	Memory uint32 // m, in kibibytes
+	Time   uint32 // t, the number of passes
+	Lanes  uint32 // p, the degree of parallelism
+
+	// KeyID identifies the secret key used to compute the hash, if any.
+	// It is at most 8 bytes long.
+	// The secret key itself is never stored in the hash.
+	KeyID []byte
+
+	// Data is the associated data, if any.
+	// It is at most 32 bytes long.
+	Data []byte
+
+	Salt []byte
+	Key  []byte
+}
+
+var (
+	// ErrMismatch is returned by Verify when the password does not match the hash.
+	ErrMismatch = errors.New("argon: password does not match")
+
+	// ErrInvalidHash matches any *HashError when used with errors.Is.
+	ErrInvalidHash = errors.New("argon: invalid hash")
+)
+
+// Errors describing why an encoded hash could not be parsed.
+// They are wrapped in a HashError.
+var (
+	ErrMalformed    = errors.New("malformed")
+	ErrNonCanonical = errors.New("not in canonical form")
+	ErrOutOfRange   = errors.New("value out of range")
+	ErrUnsupported  = errors.New("unsupported")
+)
+
+// A HashError is returned by ParseHash when an encoded hash is invalid.
+type HashError struct {
+	Field string // the field that could not be parsed, such as "m" or "salt"
+	Err   error  // the reason; one of ErrMalformed, ErrNonCanonical, ErrOutOfRange, or ErrUnsupported
+}
+
+func (e *HashError) Error() string {
+	return "argon: invalid hash: " + e.Field + ": " + e.Err.Error()
+}
+
+func (e *HashError) Unwrap() error { return e.Err }
+
+func (e *HashError) Is(target error) bool { return target == ErrInvalidHash }
+
+var b64 = base64.RawStdEncoding.Strict()
+
+// String returns the hash in PHC string format.
+func (h *Hash) String() string {
+	var sb strings.Builder
+	sb.WriteString("$")
+	sb.WriteString(h.Variant.String())
+	sb.WriteString("$v=")
+	sb.WriteString(strconv.FormatUint(uint64(h.version()), 10))
+	sb.WriteString("$m=")
+	sb.WriteString(strconv.FormatUint(uint64(h.Memory), 10))
+	sb.WriteString(",t=")
+	sb.WriteString(strconv.FormatUint(uint64(h.Time), 10))
+	sb.WriteString(",p=")
+	sb.WriteString(strconv.FormatUint(uint64(h.Lanes), 10))
+	if len(h.KeyID) > 0 {
+		sb.WriteString(",keyid=")
+		sb.WriteString(b64.EncodeToString(h.KeyID))
+	}
+	if len(h.Data) > 0 {
+		sb.WriteString(",data=")
+		sb.WriteString(b64.EncodeToString(h.Data))
+	}
+	sb.WriteString("$")
+	sb.WriteString(b64.EncodeToString(h.Salt))
+	sb.WriteString("$")
+	sb.WriteString(b64.EncodeToString(h.Key))
+	return sb.String()
+}
+
+// version returns the version, filling in the default as Params does.
+func (h *Hash) version() uint32 {
+	if h.Version == 0 {
+		return Version13
+	}
+	return h.Version
+}
+
+// ParseHash parses a hash in PHC string format.
+//
+// The version field may be omitted, in which case it defaults to Version10,
+// as in libargon2. All other fields must be in canonical form:
+// numbers must not have leading zeros or signs,
+// and base64 fields must be unpadded and must not have trailing bits set.
+// As in libargon2, m must be at least 8 times p,
+// so that the hash uses the memory it declares.
+//
+// If the string is invalid, ParseHash returns a *HashError.
+func ParseHash(s string) (*Hash, error) {
+	fields := strings.Split(s, "$")
+	if len(fields) < 5 || fields[0] != "" {
+		return nil, &HashError{"hash", ErrMalformed}
+	}
+	fields = fields[1:]
+
+	h := new(Hash)
+	switch fields[0] {
+	case "argon2d":
+		h.Variant = Argon2d
+	case "argon2i":
+		h.Variant = Argon2i
+	case "argon2id":
+		h.Variant = Argon2id
+	default:
+		return nil, &HashError{"variant", ErrUnsupported}
+	}
+	fields = fields[1:]
+
+	h.Version = Version10
+	if strings.HasPrefix(fields[0], "v=") {
+		v, err := parseUint32(fields[0][len("v="):])
+		if err != nil {
+			return nil, &HashError{"v", err}
+		}
+		if v != Version10 && v != Version13 {
+			return nil, &HashError{"v", ErrUnsupported}
+		}
+		h.Version = v
+		fields = fields[1:]
+	}
+
+	if len(fields) != 3 {
+		return nil, &HashError{"hash", ErrMalformed}
+	}
+
+	if err := h.parseParams(fields[0]); err != nil {
+		return nil, err
+	}
+
+	var err error
+	h.Salt, err = decodeBase64(fields[1])
+	if err != nil {
+		return nil, &HashError{"salt", err}
+	}
+	if len(h.Salt) < minSalt || int64(len(h.Salt)) > maxSalt {
+		return nil, &HashError{"salt", ErrOutOfRange}
+	}
+
+	h.Key, err = decodeBase64(fields[2])
+	if err != nil {
+		return nil, &HashError{"hash", err}
+	}
+	if len(h.Key) < minTag || int64(len(h.Key)) > maxTag {
+		return nil, &HashError{"hash", ErrOutOfRange}
+	}
+
+	return h, nil
+}
+
+// Verify reports whether the password matches an encoded hash.
+// It returns nil if the password matches, ErrMismatch if it does not,
+// or a *HashError if the hash cannot be parsed.
+//
+// The password is hashed with the variant, version, cost parameters,
+// and associated data recorded in the encoded hash,
+// and the result is compared in constant time.
+func Verify(encoded string, password []byte) error {
+	return VerifyWithSecret(encoded, password, nil)
+}
+
+// VerifyWithSecret is like Verify, for hashes computed with a secret key.
+// The secret is not stored in the encoded hash
+// and must be supplied by the caller.
+func VerifyWithSecret(encoded string, password, secret []byte) error {
+	h, err := ParseHash(encoded)
+	if err != nil {
+		return err
+	}
+	return h.verify(password, secret, nil)
+}
+
+// verify reports whether the password matches the hash.
+// If exec is not nil, its Threads, Limiter, OffHeap, and SecureMemory
+// fields, which do not affect the derived key, control the computation.
+func (h *Hash) verify(password, secret []byte, exec *Params) error {
+	p := h.Params()
+	if exec != nil {
+		p.Threads = exec.Threads
+		p.Limiter = exec.Limiter
+		p.OffHeap = exec.OffHeap
+		p.SecureMemory = exec.SecureMemory
+	}
+	key, err := p.KeyWithSecret(password, h.Salt, secret, h.Data)
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare(key, h.Key) != 1 {
+		return ErrMismatch
+	}
+	return nil
+}
+
+// Params returns the parameters used to compute the hash.
+func (h *Hash) Params() Params {
+	return Params{
+		Variant:   h.Variant,
+		Version:   h.Version,
+		Time:      h.Time,
+		Memory:    h.Memory,
+		Lanes:     h.Lanes,
+		TagLength: uint32(len(h.Key)),
+	}
+}
+
+// parseParams parses the comma-separated parameter list.
+// The m, t, and p parameters are required and must appear in that order,
+// optionally followed by keyid and data, also in that order.
+func (h *Hash) parseParams(s string) error {
+	params := strings.Split(s, ",")
+	if len(params) < 3 {
+		return &HashError{"params", ErrMalformed}
+	}
+	optional := []string{"keyid", "data"}
+	for i, param := range params {
+		eq := strings.IndexByte(param, '=')
+		if eq < 0 {
+			return &HashError{"params", ErrMalformed}
+		}
+		name, value := param[:eq], param[eq+1:]
+		if i < 3 {
+			if name != "mtp"[i:i+1] {
+				return &HashError{"params", ErrMalformed}
+			}
+		} else {
+			for len(optional) > 0 && optional[0] != name {
+				optional = optional[1:]
+			}
+			if len(optional) == 0 {
+				return &HashError{"params", ErrMalformed}
+			}
+			optional = optional[1:]
+		}
+
+		var err error
+		switch name {
+		case "m":
+			h.Memory, err = parseUint32(value)
+			if err == nil && h.Memory < minMemory {
+				err = ErrOutOfRange
+			}
+		case "t":
+			h.Time, err = parseUint32(value)
+			if err == nil && h.Time < 1 {
+				err = ErrOutOfRange
+			}
+		case "p":
+			h.Lanes, err = parseUint32(value)
+			if err == nil && (h.Lanes < 1 || h.Lanes > maxPar) {
+				err = ErrOutOfRange
+			}
+		case "keyid":
+			h.KeyID, err = decodeBase64(value)
+			if err == nil && (len(h.KeyID) == 0 || len(h.KeyID) > maxKeyID) {
+				err = ErrOutOfRange
+			}
+		case "data":
+			h.Data, err = decodeBase64(value)
+			if err == nil && (len(h.Data) == 0 || len(h.Data) > maxHashData) {
+				err = ErrOutOfRange
+			}
+		}
+		if err != nil {
+			return &HashError{name, err}
+		}
+	}
+	// Each lane needs at least 8 blocks; libargon2 rejects
+	// rather than rounds up a smaller m
+	if h.Memory < 8*h.Lanes {
+		return &HashError{"m", ErrOutOfRange}
+	}
+	return nil
+}
+
+// parseUint32 parses a decimal number in canonical form.
+func parseUint32(s string) (uint32, error) {
+	if s == "" {
+		return 0, ErrMalformed
+	}
+	for _, c := range []byte(s) {
+		if c < '0' || c > '9' {
+			return 0, ErrMalformed
+		}
+	}
+	if len(s) > 1 && s[0] == '0' {
+		return 0, ErrNonCanonical
+	}
+	v, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		return 0, ErrOutOfRange
+	}
+	return uint32(v), nil
+}
+
+// decodeBase64 decodes unpadded base64.
+// Padded input and input with non-zero trailing bits
+// are rejected as non-canonical.
+func decodeBase64(s string) ([]byte, error) {
+	if strings.ContainsAny(s, "\r\n") {
+		// The base64 package ignores newlines
+		return nil, ErrMalformed
+	}
+	b, err := b64.DecodeString(s)
+	if err == nil {
+		return b, nil
+	}
+	if _, err := base64.StdEncoding.DecodeString(s); err == nil {
+		return nil, ErrNonCanonical
+	}
+	if _, err := base64.RawStdEncoding.DecodeString(s); err == nil {
+		return nil, ErrNonCanonical
+	}
+	return nil, ErrMalformed
+}
@@ -0,0 +1,244 @@
+package argon2
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func ExampleParseHash() {
+	pw := []byte("password")
+	salt := []byte("somesalt")
+
+	key, err := Argon2id.Key(pw, salt, 3, 4, 64, 32)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	h := &Hash{
+		Variant: Argon2id,
+		Version: Version13,
+		Memory:  64,
+		Time:    3,
+		Lanes:   4,
+		Salt:    salt,
+		Key:     key,
+	}
+	fmt.Println(h)
+
+	h, err = ParseHash(h.String())
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(h.Variant, h.Memory, h.Time, h.Lanes)
+	// Output:
+	// $argon2id$v=19$m=64,t=3,p=4$c29tZXNhbHQ$T4fNMJtyzPmC46C+DDai6FF5I8o+6vKMbvxnbQkvttU
+	// argon2id 64 3 4
+}
+
+// Hashes from the libargon2 test suite
+func TestParseHash_Reference(t *testing.T) {
+	var tests = []struct {
+		s    string
+		want Hash
+	}{
+		{
+			s: "$argon2i$v=19$m=256,t=2,p=1$c29tZXNhbHQ$iekCn0Y3spW+sCcFanM2xBT63UP2sghkUoHLIUpWRS8",
+			want: Hash{Variant: Argon2i, Version: Version13, Memory: 256, Time: 2, Lanes: 1,
+				Salt: []byte("somesalt"),
+				Key:  []byte{0x89, 0xe9, 0x02, 0x9f, 0x46, 0x37, 0xb2, 0x95, 0xbe, 0xb0, 0x27, 0x05, 0x6a, 0x73, 0x36, 0xc4, 0x14, 0xfa, 0xdd, 0x43, 0xf6, 0xb2, 0x08, 0x64, 0x52, 0x81, 0xcb, 0x21, 0x4a, 0x56, 0x45, 0x2f},
+			},
+		},
+		{
+			s: "$argon2i$m=256,t=2,p=1$c29tZXNhbHQ$/U3YPXYsSb3q9XxHvc0MLxur+GP960kN9j7emXX8zwY",
+			want: Hash{Variant: Argon2i, Version: Version10, Memory: 256, Time: 2, Lanes: 1,
+				Salt: []byte("somesalt"),
+				Key:  []byte{0xfd, 0x4d, 0xd8, 0x3d, 0x76, 0x2c, 0x49, 0xbd, 0xea, 0xf5, 0x7c, 0x47, 0xbd, 0xcd, 0x0c, 0x2f, 0x1b, 0xab, 0xf8, 0x63, 0xfd, 0xeb, 0x49, 0x0d, 0xf6, 0x3e, 0xde, 0x99, 0x75, 0xfc, 0xcf, 0x06},
+			},
+		},
+	}
+	for _, tt := range tests {
+		h, err := ParseHash(tt.s)
+		if err != nil {
+			t.Errorf("%s: %v", tt.s, err)
+			continue
+		}
+		if !reflect.DeepEqual(*h, tt.want) {
+			t.Errorf("%s: got %+v, want %+v", tt.s, *h, tt.want)
+		}
+
+		out := make([]byte, len(h.Key))
+		argon2(context.Background(), out, []byte("password"), h.Salt, nil, nil, h.Lanes, h.Memory, h.Time, h.Variant, h.Version, 1, nil, nil, nil)
+		if !bytes.Equal(out, h.Key) {
+			t.Errorf("%s: computed % x", tt.s, out)
+		}
+	}
+}
+
+func ExampleVerify() {
+	hash := "$argon2id$v=19$m=64,t=3,p=4$c29tZXNhbHQ$T4fNMJtyzPmC46C+DDai6FF5I8o+6vKMbvxnbQkvttU"
+
+	fmt.Println(Verify(hash, []byte("password")))
+	fmt.Println(Verify(hash, []byte("hunter2")))
+	// Output:
+	// <nil>
+	// argon: password does not match
+}
+
+func TestVerify(t *testing.T) {
+	pw := []byte("password")
+	secret := repeat(3, 8)
+	h := &Hash{
+		Variant: Argon2i,
+		Version: Version13,
+		Memory:  32,
+		Time:    2,
+		Lanes:   2,
+		KeyID:   []byte{1},
+		Data:    repeat(4, 12),
+		Salt:    repeat(5, 16),
+	}
+	var err error
+	h.Key, err = h.Variant.KeyWithSecret(pw, h.Salt, secret, h.Data, 2, 2, 32, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyWithSecret(h.String(), pw, secret); err != nil {
+		t.Errorf("VerifyWithSecret: got %v, want nil", err)
+	}
+	if err := Verify(h.String(), pw); err != ErrMismatch {
+		t.Errorf("Verify without secret: got %v, want ErrMismatch", err)
+	}
+	if err := VerifyWithSecret(h.String(), []byte("passwore"), secret); err != ErrMismatch {
+		t.Errorf("VerifyWithSecret wrong password: got %v, want ErrMismatch", err)
+	}
+
+	h.Data[0] ^= 1
+	if err := VerifyWithSecret(h.String(), pw, secret); err != ErrMismatch {
+		t.Errorf("VerifyWithSecret wrong data: got %v, want ErrMismatch", err)
+	}
+	h.Data[0] ^= 1
+
+	h.Version = Version10
+	if err := VerifyWithSecret(h.String(), pw, secret); err != ErrMismatch {
+		t.Errorf("VerifyWithSecret wrong version: got %v, want ErrMismatch", err)
+	}
+	h.Version = Version13
+
+	h.Variant = Argon2id
+	if err := VerifyWithSecret(h.String(), pw, secret); err != ErrMismatch {
+		t.Errorf("VerifyWithSecret wrong variant: got %v, want ErrMismatch", err)
+	}
+
+	err = Verify("$argon2i$v=19$m=256,t=2,p=1$c29tZXNhbHQ", pw)
+	if !errors.Is(err, ErrInvalidHash) {
+		t.Errorf("Verify malformed hash: got %v, want ErrInvalidHash", err)
+	}
+	if errors.Is(err, ErrMismatch) {
+		t.Errorf("Verify malformed hash: got ErrMismatch")
+	}
+}
+
+func TestHash_RoundTrip(t *testing.T) {
+	h := &Hash{
+		Variant: Argon2d,
+		Version: Version10,
+		Memory:  1 << 20,
+		Time:    10,
+		Lanes:   255,
+		KeyID:   []byte{1, 2, 3},
+		Data:    repeat(4, 32),
+		Salt:    repeat(5, 16),
+		Key:     repeat(6, 64),
+	}
+	s := h.String()
+	h2, err := ParseHash(s)
+	if err != nil {
+		t.Fatalf("%s: %v", s, err)
+	}
+	if !reflect.DeepEqual(h, h2) {
+		t.Errorf("%s: got %+v, want %+v", s, h2, h)
+	}
+	if s2 := h2.String(); s2 != s {
+		t.Errorf("got %s, want %s", s2, s)
+	}
+}
+
+// A zero Version means Version13, as in Params
+func TestHash_ZeroVersion(t *testing.T) {
+	pw := []byte("password")
+	p := Params{Variant: Argon2id, Time: 1, Memory: 64, Lanes: 2, TagLength: 16}
+	key, err := p.Key(pw, repeat(5, 16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := &Hash{Variant: p.Variant, Memory: p.Memory, Time: p.Time, Lanes: p.Lanes, Salt: repeat(5, 16), Key: key}
+	s := h.String()
+	h2, err := ParseHash(s)
+	if err != nil {
+		t.Fatalf("%s: %v", s, err)
+	}
+	if h2.Version != Version13 {
+		t.Errorf("%s: got version %#x, want %#x", s, h2.Version, Version13)
+	}
+	if err := Verify(s, pw); err != nil {
+		t.Errorf("Verify(%s): %v", s, err)
+	}
+}
+
+func TestParseHash_Err(t *testing.T) {
+	const (
+		salt = "c29tZXNhbHQ"
+		key  = "iekCn0Y3spW+sCcFanM2xBT63UP2sghkUoHLIUpWRS8"
+	)
+	var tests = []struct {
+		s     string
+		field string
+		err   error
+	}{
+		{"", "hash", ErrMalformed},
+		{"argon2i$v=19$m=256,t=2,p=1$" + salt + "$" + key, "hash", ErrMalformed},
+		{"$argon2x$v=19$m=256,t=2,p=1$" + salt + "$" + key, "variant", ErrUnsupported},
+		{"$argon2i$v=18$m=256,t=2,p=1$" + salt + "$" + key, "v", ErrUnsupported},
+		{"$argon2i$v=019$m=256,t=2,p=1$" + salt + "$" + key, "v", ErrNonCanonical},
+		{"$argon2i$v=19$m=256,t=2,p=1$" + salt, "hash", ErrMalformed},
+		{"$argon2i$v=19$m=256,t=2,p=1$" + salt + "$" + key + "$", "hash", ErrMalformed},
+		{"$argon2i$v=19$t=2,m=256,p=1$" + salt + "$" + key, "params", ErrMalformed},
+		{"$argon2i$v=19$m=256,t=2$" + salt + "$" + key, "params", ErrMalformed},
+		{"$argon2i$v=19$m=256,t=2,p=1,x=1$" + salt + "$" + key, "params", ErrMalformed},
+		{"$argon2i$v=19$m=256,t=2,p=1,data=AQID,keyid=AQID$" + salt + "$" + key, "params", ErrMalformed},
+		{"$argon2i$v=19$m=0256,t=2,p=1$" + salt + "$" + key, "m", ErrNonCanonical},
+		{"$argon2i$v=19$m=+256,t=2,p=1$" + salt + "$" + key, "m", ErrMalformed},
+		{"$argon2i$v=19$m=,t=2,p=1$" + salt + "$" + key, "m", ErrMalformed},
+		{"$argon2i$v=19$m=4,t=2,p=1$" + salt + "$" + key, "m", ErrOutOfRange},
+		{"$argon2i$v=19$m=4294967296,t=2,p=1$" + salt + "$" + key, "m", ErrOutOfRange},
+		{"$argon2i$v=19$m=31,t=2,p=4$" + salt + "$" + key, "m", ErrOutOfRange},
+		{"$argon2i$v=19$m=256,t=0,p=1$" + salt + "$" + key, "t", ErrOutOfRange},
+		{"$argon2i$v=19$m=256,t=2,p=0$" + salt + "$" + key, "p", ErrOutOfRange},
+		{"$argon2i$v=19$m=256,t=2,p=256$" + salt + "$" + key, "p", ErrOutOfRange},
+		{"$argon2i$v=19$m=256,t=2,p=1,keyid=AQIDBAUGBwgJ$" + salt + "$" + key, "keyid", ErrOutOfRange},
+		{"$argon2i$v=19$m=256,t=2,p=1,data=$" + salt + "$" + key, "data", ErrOutOfRange},
+		{"$argon2i$v=19$m=256,t=2,p=1$c29tZXNhbHQ=$" + key, "salt", ErrNonCanonical},
+		{"$argon2i$v=19$m=256,t=2,p=1$c29tZXNhbHR$" + key, "salt", ErrNonCanonical},
+		{"$argon2i$v=19$m=256,t=2,p=1$c29tZX\nNhbHQ$" + key, "salt", ErrMalformed},
+		{"$argon2i$v=19$m=256,t=2,p=1$c29tZQ$" + key, "salt", ErrOutOfRange},
+		{"$argon2i$v=19$m=256,t=2,p=1$" + salt + "$!!!", "hash", ErrMalformed},
+		{"$argon2i$v=19$m=256,t=2,p=1$" + salt + "$AQID", "hash", ErrOutOfRange},
+	}
+	for _, tt := range tests {
+		_, err := ParseHash(tt.s)
+		var herr *HashError
+		if !errors.As(err, &herr) {
+			t.Errorf("%q: got %v, want HashError", tt.s, err)
+			continue
+		}
+		if herr.Field != tt.field || herr.Err != tt.err {
+			t.Errorf("%q: got %v, want %s: %v", tt.s, err, tt.field, tt.err)
+		}
+	}
+}
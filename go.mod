@@ -1,5 +1,3 @@
 module github.com/magical/argon2
 
 go 1.13
-
-require github.com/dchest/blake2b v1.0.0
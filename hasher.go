@@ -0,0 +1,87 @@
+package argon2
+
+import (
+	"context"
+	"sync"
+)
+
+// A Hasher derives keys using a fixed set of parameters.
+//
+// Unlike Key, which allocates a new matrix for each call,
+// a Hasher keeps a pool of matrices and reuses them between calls.
+// Once the pool is warm, deriving a key with a single goroutine
+// does not allocate any memory.
+//
+// If the parameters have a Limiter, only the matrices in use
+// count against its budget; idle matrices in the pool
+// may be freed by the garbage collector at any time.
+// If they have OffHeap or SecureMemory set, matrices are not pooled;
+// each call maps its own matrix and unmaps it before returning.
+//
+// A Hasher is safe for concurrent use by multiple goroutines.
+type Hasher struct {
+	params Params
+	pool   sync.Pool
+}
+
+// NewHasher returns a Hasher which derives keys with the given parameters.
+func NewHasher(p Params) (*Hasher, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	h := &Hasher{params: p}
+	m := p.EffectiveMemory()
+	threads := p.threads()
+	h.pool.New = func() interface{} {
+		ws, _ := newWorkspace(m, p.Lanes, threads, nil) // the heap never fails
+		return ws
+	}
+	return h, nil
+}
+
+// Params returns the parameters used by the Hasher.
+func (h *Hasher) Params() Params {
+	return h.params
+}
+
+// Key derives a key from the password and salt, writing it to out.
+// The length of out must be equal to the TagLength parameter.
+func (h *Hasher) Key(out, password, salt []byte) error {
+	return h.KeyWithSecret(out, password, salt, nil, nil)
+}
+
+// KeyWithSecret is like Key but additionally mixes a secret key
+// and associated data into the derived key.
+// See Variant.KeyWithSecret.
+func (h *Hasher) KeyWithSecret(out, password, salt, secret, data []byte) error {
+	return h.KeyContext(context.Background(), out, password, salt, secret, data)
+}
+
+// KeyContext is like KeyWithSecret but stops early if ctx is canceled,
+// including while waiting for the Limiter, if any.
+// The secret and data may be nil.
+// See Variant.KeyContext.
+func (h *Hasher) KeyContext(ctx context.Context, out, password, salt, secret, data []byte) error {
+	p := &h.params
+	if err := checkInputs(password, salt, secret, data); err != nil {
+		return err
+	}
+	if len(out) != int(p.TagLength) {
+		return ErrInvalidTagLength
+	}
+	if p.Limiter != nil {
+		n := int64(p.EffectiveMemory())
+		if err := p.Limiter.Acquire(ctx, n); err != nil {
+			return err
+		}
+		defer p.Limiter.Release(n)
+	}
+	if p.OffHeap || p.SecureMemory {
+		// Off-heap matrices are not pooled, so that they are unmapped promptly
+		return argon2(ctx, out, password, salt, secret, data, p.Lanes, p.Memory, p.Time, p.Variant, p.version(), p.threads(), p.Progress, p.Tracer, p.allocator())
+	}
+	ws := h.pool.Get().(*workspace)
+	err := ws.argon2(ctx, out, password, salt, secret, data, p.Lanes, p.Memory, p.Time, p.Variant, p.version(), p.Progress, p.Tracer)
+	h.pool.Put(ws)
+	return err
+}
@@ -0,0 +1,64 @@
+package argon2
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+)
+
+func TestHasher(t *testing.T) {
+	pw := repeat(0x0, 16)
+	salt := repeat(0x1, 8)
+	for _, v := range []Variant{Argon2d, Argon2i, Argon2id} {
+		h, err := NewHasher(Params{Variant: v, Time: 3, Memory: 64, Lanes: 4, TagLength: 32})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := v.Key(pw, salt, 3, 4, 64, 32)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Reusing the matrix must not change the result
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 4; j++ {
+					out := make([]byte, 32)
+					if err := h.Key(out, pw, salt); err != nil {
+						t.Error(err)
+						return
+					}
+					if !bytes.Equal(out, want) {
+						t.Errorf("%s: got % x, want % x", v, out, want)
+					}
+				}
+			}()
+		}
+		wg.Wait()
+	}
+}
+
+// Set when the race detector is enabled
+var raceEnabled bool
+
+func TestHasherAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("sync.Pool drops items at random under the race detector")
+	}
+	pw := repeat(0x0, 16)
+	salt := repeat(0x1, 8)
+	out := make([]byte, 32)
+	h, err := NewHasher(Params{Variant: Argon2id, Time: 3, Memory: 32, Lanes: 1, TagLength: 32})
+	if err != nil {
+		t.Fatal(err)
+	}
+	allocs := testing.AllocsPerRun(100, func() {
+		h.Key(out, pw, salt)
+	})
+	if allocs > 0 {
+		t.Errorf("%v allocs, want 0", allocs)
+	}
+}
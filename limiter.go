@@ -0,0 +1,158 @@
+package argon2
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrMemoryBudget is returned when a computation needs more memory
+// than the whole budget of its Limiter, so that it could never be admitted.
+var ErrMemoryBudget = errors.New("argon: memory exceeds budget")
+
+// A Limiter bounds the total memory used by concurrent computations.
+//
+// Every computation with the Limiter set in its Params reserves
+// its matrix of EffectiveMemory kibibytes before allocating it,
+// and releases it once the key is derived.
+// While the reservations in flight would exceed the budget,
+// new computations wait their turn in first-come, first-served order,
+// so that a large computation is not starved by a stream of small ones.
+//
+// A single Limiter is typically shared by all the Params and Hashers
+// in a process. It is safe for concurrent use by multiple goroutines.
+type Limiter struct {
+	mu      sync.Mutex
+	budget  int64     // in kibibytes
+	used    int64     // in kibibytes
+	waiters []*waiter // in order of arrival
+}
+
+type waiter struct {
+	n     int64
+	ready chan struct{} // closed when the memory is reserved
+}
+
+// NewLimiter returns a Limiter with a budget of the given number of kibibytes.
+//
+// If budget is zero, it is set to half of the soft memory limit
+// of the Go runtime (see runtime/debug.SetMemoryLimit),
+// leaving the other half for the rest of the program.
+// If no memory limit is set, the budget is unlimited.
+// NewLimiter panics if budget is negative.
+func NewLimiter(budget int64) *Limiter {
+	if budget < 0 {
+		panic("argon2: negative Limiter budget")
+	}
+	if budget == 0 {
+		budget = runtimeMemoryLimit() / 1024 / 2
+	}
+	return &Limiter{budget: budget}
+}
+
+// Budget returns the budget of the Limiter, in kibibytes.
+func (l *Limiter) Budget() int64 {
+	return l.budget
+}
+
+// InUse returns the number of kibibytes currently reserved.
+func (l *Limiter) InUse() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.used
+}
+
+// Acquire reserves n kibibytes, waiting until they are available.
+// If ctx is canceled first, it returns ctx.Err() and reserves nothing.
+// If n is more than the budget, it returns ErrMemoryBudget immediately.
+// Acquire panics if n is negative.
+func (l *Limiter) Acquire(ctx context.Context, n int64) error {
+	checkReservation(n)
+	l.mu.Lock()
+	if n > l.budget {
+		l.mu.Unlock()
+		return ErrMemoryBudget
+	}
+	if len(l.waiters) == 0 && l.used+n <= l.budget {
+		l.used += n
+		l.mu.Unlock()
+		return nil
+	}
+	w := &waiter{n: n, ready: make(chan struct{})}
+	l.waiters = append(l.waiters, w)
+	l.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	select {
+	case <-w.ready:
+		// Reserved anyway; give it back
+		l.used -= n
+	default:
+		for i, x := range l.waiters {
+			if x == w {
+				l.waiters = append(l.waiters[:i], l.waiters[i+1:]...)
+				break
+			}
+		}
+	}
+	// The waiters behind w may fit now
+	l.admit()
+	return ctx.Err()
+}
+
+// TryAcquire reserves n kibibytes if they are available without waiting,
+// and reports whether it did.
+// TryAcquire panics if n is negative.
+func (l *Limiter) TryAcquire(n int64) bool {
+	checkReservation(n)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.waiters) == 0 && l.used+n <= l.budget {
+		l.used += n
+		return true
+	}
+	return false
+}
+
+// Release returns n kibibytes reserved by Acquire or TryAcquire.
+// It panics if n is negative or more than is reserved.
+func (l *Limiter) Release(n int64) {
+	checkReservation(n)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.used -= n
+	if l.used < 0 {
+		panic("argon2: Limiter released more than acquired")
+	}
+	l.admit()
+}
+
+// checkReservation panics if n is negative,
+// which would enlarge the budget instead of using it.
+func checkReservation(n int64) {
+	if n < 0 {
+		panic("argon2: negative Limiter reservation")
+	}
+}
+
+// admit reserves memory for the waiters at the head of the queue
+// for as long as they fit. l.mu must be held.
+func (l *Limiter) admit() {
+	for len(l.waiters) > 0 {
+		w := l.waiters[0]
+		if l.used+w.n > l.budget {
+			break
+		}
+		l.used += w.n
+		close(w.ready)
+		l.waiters[0] = nil
+		l.waiters = l.waiters[1:]
+	}
+}
@@ -0,0 +1,193 @@
+package argon2
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLimiter(t *testing.T) {
+	l := NewLimiter(10)
+	ctx := context.Background()
+	if err := l.Acquire(ctx, 6); err != nil {
+		t.Fatal(err)
+	}
+	if !l.TryAcquire(4) {
+		t.Errorf("TryAcquire(4) = false with 4 available")
+	}
+	if l.TryAcquire(1) {
+		t.Errorf("TryAcquire(1) = true with none available")
+	}
+	if got := l.InUse(); got != 10 {
+		t.Errorf("InUse() = %d, want 10", got)
+	}
+	l.Release(6)
+	l.Release(4)
+	if got := l.InUse(); got != 0 {
+		t.Errorf("InUse() = %d, want 0", got)
+	}
+
+	if err := l.Acquire(ctx, 11); err != ErrMemoryBudget {
+		t.Errorf("Acquire(11): got %v, want ErrMemoryBudget", err)
+	}
+
+	for _, tt := range []struct {
+		name string
+		f    func()
+	}{
+		{"NewLimiter(-1)", func() { NewLimiter(-1) }},
+		{"Acquire(-5)", func() { l.Acquire(ctx, -5) }},
+		{"TryAcquire(-5)", func() { l.TryAcquire(-5) }},
+		{"Release(-5)", func() { l.Release(-5) }},
+	} {
+		if !panics(tt.f) {
+			t.Errorf("%s did not panic", tt.name)
+		}
+	}
+	if got := l.InUse(); got != 0 {
+		t.Errorf("InUse() = %d after negative reservations, want 0", got)
+	}
+}
+
+// panics reports whether f panics.
+func panics(f func()) (panicked bool) {
+	defer func() {
+		if recover() != nil {
+			panicked = true
+		}
+	}()
+	f()
+	return false
+}
+
+// waitQueued waits until l has n waiters.
+func waitQueued(t *testing.T, l *Limiter, n int) {
+	t.Helper()
+	for i := 0; ; i++ {
+		l.mu.Lock()
+		queued := len(l.waiters)
+		l.mu.Unlock()
+		if queued == n {
+			return
+		}
+		if i == 1000 {
+			t.Fatalf("%d waiters, want %d", queued, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// Checks that waiters are admitted in order of arrival
+func TestLimiterFIFO(t *testing.T) {
+	l := NewLimiter(10)
+	ctx := context.Background()
+	l.Acquire(ctx, 8)
+
+	done := make(chan int64)
+	for i, n := range []int64{5, 1, 6} {
+		go func(n int64) {
+			if err := l.Acquire(ctx, n); err != nil {
+				t.Error(err)
+			}
+			done <- n
+		}(n)
+		// The request for 1 would fit, but must queue behind the request for 5
+		waitQueued(t, l, i+1)
+	}
+	if l.TryAcquire(1) {
+		t.Errorf("TryAcquire(1) = true with waiters queued")
+	}
+
+	l.Release(8)
+	if a, b := <-done, <-done; a+b != 6 {
+		t.Errorf("admitted %d and %d, want 5 and 1", a, b)
+	}
+	waitQueued(t, l, 1)
+	if got := l.InUse(); got != 6 {
+		t.Errorf("InUse() = %d, want 6", got)
+	}
+
+	l.Release(5)
+	if n := <-done; n != 6 {
+		t.Errorf("admitted %d, want 6", n)
+	}
+	if got := l.InUse(); got != 7 {
+		t.Errorf("InUse() = %d, want 7", got)
+	}
+}
+
+func TestLimiterCancel(t *testing.T) {
+	l := NewLimiter(10)
+	l.Acquire(context.Background(), 8)
+
+	// A canceled waiter at the head of the queue
+	// must not hold up the waiters behind it
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error)
+	go func() { errc <- l.Acquire(ctx, 5) }()
+	waitQueued(t, l, 1)
+	go func() { errc <- l.Acquire(context.Background(), 2) }()
+	waitQueued(t, l, 2)
+
+	cancel()
+	got := []error{<-errc, <-errc}
+	if !(got[0] == context.Canceled && got[1] == nil) && !(got[0] == nil && got[1] == context.Canceled) {
+		t.Errorf("got %v, want one nil and one context.Canceled", got)
+	}
+	if n := l.InUse(); n != 10 {
+		t.Errorf("InUse() = %d, want 10", n)
+	}
+}
+
+func TestParamsLimiter(t *testing.T) {
+	pw := repeat(0x0, 16)
+	salt := repeat(0x1, 8)
+	l := NewLimiter(64)
+	p := Params{Variant: Argon2id, Time: 1, Memory: 64, Lanes: 1, TagLength: 32, Limiter: l}
+	want, err := Argon2id.Key(pw, salt, 1, 1, 64, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Only one computation fits at a time
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			key, err := p.Key(pw, salt)
+			if err != nil {
+				t.Error(err)
+			} else if string(key) != string(want) {
+				t.Errorf("got %x, want %x", key, want)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := l.InUse(); n != 0 {
+		t.Errorf("InUse() = %d after all computations, want 0", n)
+	}
+
+	// The Hasher waits for the Limiter too
+	h, err := NewHasher(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Acquire(context.Background(), 1)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	out := make([]byte, 32)
+	if err := h.KeyContext(ctx, out, pw, salt, nil, nil); err != context.DeadlineExceeded {
+		t.Errorf("Hasher.KeyContext: got %v, want context.DeadlineExceeded", err)
+	}
+	l.Release(1)
+	if err := h.Key(out, pw, salt); err != nil || string(out) != string(want) {
+		t.Errorf("Hasher.Key = %x, %v, want %x", out, err, want)
+	}
+
+	p.Memory = 128
+	if _, err := p.Key(pw, salt); err != ErrMemoryBudget {
+		t.Errorf("Key with memory over budget: got %v, want ErrMemoryBudget", err)
+	}
+}
@@ -0,0 +1,12 @@
+//go:build go1.19
+// +build go1.19
+
+package argon2
+
+import "runtime/debug"
+
+// runtimeMemoryLimit returns the soft memory limit of the Go runtime,
+// in bytes, or math.MaxInt64 if there is none.
+func runtimeMemoryLimit() int64 {
+	return debug.SetMemoryLimit(-1)
+}
@@ -0,0 +1,12 @@
+//go:build !go1.19
+// +build !go1.19
+
+package argon2
+
+import "math"
+
+// runtimeMemoryLimit returns math.MaxInt64:
+// Go versions before 1.19 have no soft memory limit.
+func runtimeMemoryLimit() int64 {
+	return math.MaxInt64
+}
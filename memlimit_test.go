@@ -0,0 +1,23 @@
+//go:build go1.19
+// +build go1.19
+
+package argon2
+
+import (
+	"math"
+	"runtime/debug"
+	"testing"
+)
+
+func TestNewLimiterDefault(t *testing.T) {
+	old := debug.SetMemoryLimit(1 << 30)
+	defer debug.SetMemoryLimit(old)
+	if got, want := NewLimiter(0).Budget(), int64(1<<30/1024/2); got != want {
+		t.Errorf("budget with a 1 GiB limit = %d KiB, want %d", got, want)
+	}
+
+	debug.SetMemoryLimit(math.MaxInt64)
+	if got := NewLimiter(0).Budget(); got < 1<<40 {
+		t.Errorf("budget with no limit = %d KiB, want unlimited", got)
+	}
+}
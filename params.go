@@ -0,0 +1,218 @@
+package argon2
+
+import (
+	"context"
+	"errors"
+	"runtime"
+)
+
+// Params describes a set of Argon2 parameters.
+type Params struct {
+	Variant Variant
+
+	// Version is the version of the algorithm:
+	// Version10, Version13, or zero, which means Version13.
+	Version uint32
+
+	// Time is the number of passes over the memory.
+	// It must be at least 1.
+	Time uint32
+
+	// Memory is the amount of memory to use, in kibibytes.
+	// It must be at least 8.
+	// The amount of memory actually used is reported by EffectiveMemory.
+	Memory uint32
+
+	// Lanes is the degree of parallelism.
+	// It must be between 1 and 255.
+	Lanes uint32
+
+	// Threads is the maximum number of goroutines used to fill lanes.
+	// If zero, GOMAXPROCS is used.
+	// Threads does not affect the derived key.
+	Threads int
+
+	// TagLength is the length of the derived key, in bytes.
+	// It must be at least 4.
+	TagLength uint32
+
+	// Progress, if not nil, is called after each slice of each pass
+	// is finished, from the goroutine deriving the key.
+	// Pass and slice count from zero; there are four slices per pass
+	// and total passes in all.
+	Progress func(pass, slice, total uint32)
+
+	// Tracer, if not nil, receives events describing each step
+	// of the computation. Lanes are filled one at a time while tracing.
+	Tracer Tracer
+
+	// Limiter, if not nil, bounds the memory used by this computation
+	// together with all others sharing the Limiter.
+	// The computation waits until the Limiter admits it.
+	Limiter *Limiter
+
+	// OffHeap, if true, places the matrix outside the Go heap
+	// on Linux, in an anonymous memory mapping which is backed
+	// by transparent huge pages where available
+	// and unmapped as soon as the key is derived.
+	// A large matrix then neither raises the heap goal
+	// nor causes extra garbage collections.
+	// On other platforms, OffHeap has no effect.
+	// OffHeap does not affect the derived key.
+	OffHeap bool
+
+	// SecureMemory, if true, places the matrix, the buffers
+	// derived from the password, the scratch space of each goroutine,
+	// and the state of the hash functions outside the Go heap,
+	// like OffHeap, and locks them into RAM so that they are never
+	// swapped to disk and excludes them from core dumps.
+	// They are wiped and unlocked as soon as the key is derived.
+	// Only the copies Go may leave on the stack are not protected.
+	//
+	// SecureMemory is only supported on Linux.
+	// If the memory cannot be locked, usually because RLIMIT_MEMLOCK
+	// is too small, Key returns a *MemoryLockError.
+	// SecureMemory does not affect the derived key.
+	SecureMemory bool
+}
+
+// Errors returned by Params.Validate and the Key functions.
+var (
+	ErrInvalidVariant   = errors.New("argon: invalid variant")
+	ErrInvalidVersion   = errors.New("argon: invalid version")
+	ErrInvalidTime      = errors.New("argon: invalid n")
+	ErrInvalidMemory    = errors.New("argon: invalid mem")
+	ErrInvalidLanes     = errors.New("argon: invalid par")
+	ErrInvalidThreads   = errors.New("argon: invalid threads")
+	ErrInvalidTagLength = errors.New("argon: invalid tag length")
+
+	ErrPasswordTooLong = errors.New("argon: password too long")
+	ErrSaltTooShort    = errors.New("argon: salt too short")
+	ErrSaltTooLong     = errors.New("argon: salt too long")
+	ErrSecretTooLong   = errors.New("argon: secret too long")
+	ErrDataTooLong     = errors.New("argon: data too long")
+)
+
+// Validate reports whether the parameters are valid.
+// It returns one of the ErrInvalid errors if they are not.
+func (p *Params) Validate() error {
+	if p.Variant != Argon2d && p.Variant != Argon2i && p.Variant != Argon2id {
+		return ErrInvalidVariant
+	}
+	if p.Version != 0 && p.Version != Version10 && p.Version != Version13 {
+		return ErrInvalidVersion
+	}
+	if p.Time < 1 {
+		return ErrInvalidTime
+	}
+	if p.Memory < minMemory {
+		return ErrInvalidMemory
+	}
+	if p.Lanes < 1 || p.Lanes > maxPar {
+		return ErrInvalidLanes
+	}
+	if p.Threads < 0 {
+		return ErrInvalidThreads
+	}
+	if p.TagLength < minTag {
+		return ErrInvalidTagLength
+	}
+	return nil
+}
+
+// EffectiveMemory returns the amount of memory, in kibibytes,
+// that is actually used when deriving a key.
+// This is Memory rounded down to a multiple of 4*Lanes,
+// but no less than 8*Lanes.
+//
+// The parameters must be valid.
+func (p *Params) EffectiveMemory() uint32 {
+	return numBlocks(p.Memory, p.Lanes)
+}
+
+// Key derives a key from the password and salt.
+// The salt must be at least 8 bytes long.
+func (p *Params) Key(password, salt []byte) ([]byte, error) {
+	return p.KeyContext(context.Background(), password, salt, nil, nil)
+}
+
+// KeyWithSecret is like Key but additionally mixes a secret key
+// and associated data into the derived key.
+// See Variant.KeyWithSecret.
+func (p *Params) KeyWithSecret(password, salt, secret, data []byte) ([]byte, error) {
+	return p.KeyContext(context.Background(), password, salt, secret, data)
+}
+
+// KeyContext is like KeyWithSecret but stops early if ctx is canceled.
+// The secret and data may be nil.
+// See Variant.KeyContext.
+func (p *Params) KeyContext(ctx context.Context, password, salt, secret, data []byte) ([]byte, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	if err := checkInputs(password, salt, secret, data); err != nil {
+		return nil, err
+	}
+	if p.Limiter != nil {
+		n := int64(p.EffectiveMemory())
+		if err := p.Limiter.Acquire(ctx, n); err != nil {
+			return nil, err
+		}
+		defer p.Limiter.Release(n)
+	}
+	output := make([]byte, p.TagLength)
+	err := argon2(ctx, output, password, salt, secret, data, p.Lanes, p.Memory, p.Time, p.Variant, p.version(), p.threads(), p.Progress, p.Tracer, p.allocator())
+	if err != nil {
+		return nil, err
+	}
+	return output, nil
+}
+
+// version returns the version, filling in the default.
+func (p *Params) version() uint32 {
+	if p.Version == 0 {
+		return Version13
+	}
+	return p.Version
+}
+
+// allocator returns the allocator for the matrix.
+func (p *Params) allocator() allocator {
+	if p.SecureMemory {
+		return secureAllocator
+	}
+	if p.OffHeap {
+		return offHeapAllocator
+	}
+	return heapAllocator{}
+}
+
+// threads returns the number of goroutines to use, filling in the default.
+func (p *Params) threads() int {
+	if p.Threads == 0 {
+		return runtime.GOMAXPROCS(0)
+	}
+	return p.Threads
+}
+
+func checkInputs(password, salt, secret, data []byte) error {
+	if int64(len(password)) > maxPassword {
+		return ErrPasswordTooLong
+	}
+
+	if int64(len(secret)) > maxSecret {
+		return ErrSecretTooLong
+	}
+
+	if int64(len(data)) > maxData {
+		return ErrDataTooLong
+	}
+
+	if len(salt) < minSalt {
+		return ErrSaltTooShort
+	} else if int64(len(salt)) > maxSalt {
+		return ErrSaltTooLong
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package argon2
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestParamsValidate(t *testing.T) {
+	valid := Params{Variant: Argon2id, Version: Version13, Time: 3, Memory: 64, Lanes: 4, TagLength: 32}
+	var tests = []struct {
+		modify func(p *Params)
+		want   error
+	}{
+		{func(p *Params) {}, nil},
+		{func(p *Params) { p.Version = 0 }, nil},
+		{func(p *Params) { p.Version = Version10 }, nil},
+		{func(p *Params) { p.Threads = 16 }, nil},
+		{func(p *Params) { p.Variant = 3 }, ErrInvalidVariant},
+		{func(p *Params) { p.Version = 0x11 }, ErrInvalidVersion},
+		{func(p *Params) { p.Time = 0 }, ErrInvalidTime},
+		{func(p *Params) { p.Memory = 7 }, ErrInvalidMemory},
+		{func(p *Params) { p.Lanes = 0 }, ErrInvalidLanes},
+		{func(p *Params) { p.Lanes = 256 }, ErrInvalidLanes},
+		{func(p *Params) { p.Threads = -1 }, ErrInvalidThreads},
+		{func(p *Params) { p.TagLength = 3 }, ErrInvalidTagLength},
+	}
+	for i, tt := range tests {
+		p := valid
+		tt.modify(&p)
+		if err := p.Validate(); err != tt.want {
+			t.Errorf("%d: %+v: got %v, want %v", i, p, err, tt.want)
+		}
+	}
+}
+
+func TestParamsEffectiveMemory(t *testing.T) {
+	var tests = []struct {
+		mem, lanes, want uint32
+	}{
+		{8, 1, 8},
+		{11, 1, 8},
+		{12, 1, 12},
+		{8, 2, 16},
+		{100, 3, 96},
+		{65536, 4, 65536},
+		{65537, 4, 65536},
+	}
+	for _, tt := range tests {
+		p := Params{Memory: tt.mem, Lanes: tt.lanes}
+		if got := p.EffectiveMemory(); got != tt.want {
+			t.Errorf("mem=%d, lanes=%d: got %d, want %d", tt.mem, tt.lanes, got, tt.want)
+		}
+	}
+}
+
+func TestParamsKey(t *testing.T) {
+	pw := zeros[:]
+	salt := ones[:]
+	p := Params{Variant: Argon2i, Time: 3, Memory: 32, Lanes: 2, Threads: 1, TagLength: 16}
+	key, err := p.Key(pw, salt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := Argon2i.Key(pw, salt, 3, 2, 32, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(key, want) {
+		t.Errorf("got % x, want % x", key, want)
+	}
+
+	if _, err := p.Key(pw, salt[:7]); err != ErrSaltTooShort {
+		t.Errorf("got %v, want %v", err, ErrSaltTooShort)
+	}
+	if _, err := Key(pw, salt, 3, 1, 8, 3); err != ErrInvalidTagLength {
+		t.Errorf("got %v, want %v", err, ErrInvalidTagLength)
+	}
+}
+
+func TestParamsProgress(t *testing.T) {
+	type call struct{ pass, slice, total uint32 }
+	var calls []call
+	p := Params{Variant: Argon2id, Time: 3, Memory: 64, Lanes: 4, TagLength: 32}
+	p.Progress = func(pass, slice, total uint32) {
+		calls = append(calls, call{pass, slice, total})
+	}
+	if _, err := p.Key(zeros[:], ones[:]); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 12 {
+		t.Fatalf("got %d calls, want 12", len(calls))
+	}
+	for i, c := range calls {
+		want := call{uint32(i / 4), uint32(i % 4), 3}
+		if c != want {
+			t.Errorf("call %d: got %v, want %v", i, c, want)
+		}
+	}
+}
@@ -0,0 +1,6 @@
+//go:build race
+// +build race
+
+package argon2
+
+func init() { raceEnabled = true }
@@ -0,0 +1,106 @@
+package argon2
+
+import "crypto/rand"
+
+// RehashSaltLength is the minimum salt length, in bytes,
+// of a hash which does not need rehashing,
+// and the length of the salts generated by VerifyAndRehash.
+// It is the length recommended by RFC 9106.
+// Params has no salt length, so it is not part of the policy.
+const RehashSaltLength = 16
+
+// NeedsRehash reports whether an encoded hash was computed
+// with weaker parameters than policy, and so should be replaced
+// the next time the password is available.
+//
+// A hash needs rehashing if its variant or version differs from the policy,
+// if its memory, passes, or tag length are less than the policy's,
+// if it uses a different number of lanes,
+// or if its salt is shorter than RehashSaltLength.
+// A hash which cannot be parsed always needs rehashing.
+// Only the fields of policy which affect the derived key are considered;
+// Threads, Progress, Tracer, Limiter, OffHeap, and SecureMemory are ignored.
+func NeedsRehash(encoded string, policy Params) bool {
+	h, err := ParseHash(encoded)
+	if err != nil {
+		return true
+	}
+	return h.needsRehash(&policy)
+}
+
+func (h *Hash) needsRehash(p *Params) bool {
+	return h.Variant != p.Variant ||
+		h.version() != p.version() ||
+		h.Memory < p.Memory ||
+		h.Time < p.Time ||
+		h.Lanes != p.Lanes ||
+		uint32(len(h.Key)) < p.TagLength ||
+		len(h.Salt) < RehashSaltLength
+}
+
+// VerifyAndRehash verifies a password against an encoded hash,
+// as in a login flow, and upgrades the hash if it is below policy.
+//
+// If the policy is invalid, VerifyAndRehash returns the error from
+// policy.Validate without checking the password.
+// If the password does not match, it returns ErrMismatch,
+// or a *HashError if the hash cannot be parsed.
+// If the password matches and NeedsRehash reports that the hash is below policy,
+// it returns a replacement hash computed with the policy parameters
+// and a fresh random salt of RehashSaltLength bytes,
+// which the caller should store.
+// Otherwise it returns an empty string and a nil error.
+//
+// The Threads, Limiter, OffHeap, and SecureMemory fields of policy
+// apply both to verifying the password and to computing the replacement hash,
+// so that the memory derived from the password gets the same protection.
+func VerifyAndRehash(encoded string, password []byte, policy Params) (string, error) {
+	return VerifyAndRehashWithSecret(encoded, password, nil, policy)
+}
+
+// VerifyAndRehashWithSecret is like VerifyAndRehash,
+// for hashes computed with a secret key.
+// The replacement hash uses the same secret key
+// and keeps the key ID and associated data of the original.
+func VerifyAndRehashWithSecret(encoded string, password, secret []byte, policy Params) (string, error) {
+	h, err := ParseHash(encoded)
+	if err != nil {
+		return "", err
+	}
+	return h.verifyAndRehash(password, secret, &policy)
+}
+
+func (h *Hash) verifyAndRehash(password, secret []byte, policy *Params) (string, error) {
+	// An invalid policy must not look like a failed login
+	// after a correct password
+	if err := policy.Validate(); err != nil {
+		return "", err
+	}
+	if err := h.verify(password, secret, policy); err != nil {
+		return "", err
+	}
+	if !h.needsRehash(policy) {
+		return "", nil
+	}
+
+	salt := make([]byte, RehashSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key, err := policy.KeyWithSecret(password, salt, secret, h.Data)
+	if err != nil {
+		return "", err
+	}
+	nh := &Hash{
+		Variant: policy.Variant,
+		Version: policy.version(),
+		Memory:  policy.Memory,
+		Time:    policy.Time,
+		Lanes:   policy.Lanes,
+		KeyID:   h.KeyID,
+		Data:    h.Data,
+		Salt:    salt,
+		Key:     key,
+	}
+	return nh.String(), nil
+}
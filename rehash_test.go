@@ -0,0 +1,152 @@
+package argon2
+
+import (
+	"fmt"
+	"testing"
+)
+
+var rehashPolicy = Params{
+	Variant:   Argon2id,
+	Version:   Version13,
+	Time:      2,
+	Memory:    32,
+	Lanes:     2,
+	TagLength: 16,
+}
+
+func TestNeedsRehash(t *testing.T) {
+	base := Hash{
+		Variant: Argon2id,
+		Version: Version13,
+		Memory:  32,
+		Time:    2,
+		Lanes:   2,
+		Salt:    repeat(1, 16),
+		Key:     repeat(2, 16),
+	}
+	tests := []struct {
+		name string
+		edit func(h *Hash)
+		want bool
+	}{
+		{"equal", func(h *Hash) {}, false},
+		{"stronger", func(h *Hash) { h.Memory, h.Time, h.Key, h.Salt = 64, 3, repeat(2, 32), repeat(1, 32) }, false},
+		{"variant", func(h *Hash) { h.Variant = Argon2i }, true},
+		{"version", func(h *Hash) { h.Version = Version10 }, true},
+		{"memory", func(h *Hash) { h.Memory = 24 }, true},
+		{"time", func(h *Hash) { h.Time = 1 }, true},
+		{"lanes", func(h *Hash) { h.Lanes = 1 }, true},
+		{"more lanes", func(h *Hash) { h.Lanes = 4 }, true},
+		{"tag", func(h *Hash) { h.Key = repeat(2, 8) }, true},
+		{"salt", func(h *Hash) { h.Salt = repeat(1, 8) }, true},
+	}
+	for _, tt := range tests {
+		h := base
+		tt.edit(&h)
+		if got := NeedsRehash(h.String(), rehashPolicy); got != tt.want {
+			t.Errorf("%s: NeedsRehash(%s) = %v, want %v", tt.name, h.String(), got, tt.want)
+		}
+	}
+
+	if !NeedsRehash("$argon2id$v=19$m=32,t=2,p=2$AQEBAQEBAQEBAQEBAQEBAQ", rehashPolicy) {
+		t.Errorf("NeedsRehash of a malformed hash = false, want true")
+	}
+
+	// A zero Version means Version13
+	p := rehashPolicy
+	p.Version = 0
+	if NeedsRehash(base.String(), p) {
+		t.Errorf("NeedsRehash with default version = true, want false")
+	}
+}
+
+func TestVerifyAndRehash(t *testing.T) {
+	pw := []byte("password")
+	secret := repeat(3, 8)
+	old := &Hash{
+		Variant: Argon2i,
+		Version: Version10,
+		Memory:  16,
+		Time:    1,
+		Lanes:   1,
+		KeyID:   []byte{1},
+		Data:    repeat(4, 12),
+		Salt:    repeat(5, 8),
+	}
+	p := old.Params()
+	p.TagLength = 32
+	var err error
+	old.Key, err = p.KeyWithSecret(pw, old.Salt, secret, old.Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := VerifyAndRehashWithSecret(old.String(), []byte("hunter2"), secret, rehashPolicy); err != ErrMismatch {
+		t.Errorf("wrong password: got %v, want ErrMismatch", err)
+	}
+
+	s, err := VerifyAndRehashWithSecret(old.String(), pw, secret, rehashPolicy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s == "" {
+		t.Fatalf("got no replacement hash")
+	}
+	h, err := ParseHash(s)
+	if err != nil {
+		t.Fatalf("%s: %v", s, err)
+	}
+	if len(h.Salt) != RehashSaltLength {
+		t.Errorf("replacement hash %s has a %d-byte salt, want %d", s, len(h.Salt), RehashSaltLength)
+	}
+	if h.needsRehash(&rehashPolicy) {
+		t.Errorf("replacement hash %s is below policy", s)
+	}
+	if string(h.KeyID) != string(old.KeyID) || string(h.Data) != string(old.Data) {
+		t.Errorf("replacement hash %s does not keep the key ID and data", s)
+	}
+	if err := VerifyWithSecret(s, pw, secret); err != nil {
+		t.Errorf("VerifyWithSecret(%s): %v", s, err)
+	}
+
+	// The replacement is up to date
+	s2, err := VerifyAndRehashWithSecret(s, pw, secret, rehashPolicy)
+	if err != nil || s2 != "" {
+		t.Errorf("VerifyAndRehashWithSecret(%s) = %q, %v, want \"\", nil", s, s2, err)
+	}
+
+	// An invalid policy is reported before the password is checked
+	for _, pw := range [][]byte{pw, []byte("hunter2")} {
+		if _, err := VerifyAndRehashWithSecret(old.String(), pw, secret, Params{}); err != ErrInvalidTime {
+			t.Errorf("invalid policy with password %q: got %v, want ErrInvalidTime", pw, err)
+		}
+	}
+
+	// Each replacement gets a fresh salt
+	s3, err := VerifyAndRehashWithSecret(old.String(), pw, secret, rehashPolicy)
+	if err != nil || s3 == s {
+		t.Errorf("second replacement = %q, %v; want a different hash", s3, err)
+	}
+}
+
+func ExampleVerifyAndRehash() {
+	stored := "$argon2id$v=19$m=64,t=3,p=4$c29tZXNhbHQ$T4fNMJtyzPmC46C+DDai6FF5I8o+6vKMbvxnbQkvttU"
+	policy := Params{
+		Variant:   Argon2id,
+		Time:      3,
+		Memory:    128,
+		Lanes:     4,
+		TagLength: 32,
+	}
+
+	newHash, err := VerifyAndRehash(stored, []byte("password"), policy)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if newHash != "" {
+		// Store newHash in place of the old hash
+		fmt.Println(NeedsRehash(stored, policy), NeedsRehash(newHash, policy))
+	}
+	// Output: true false
+}
@@ -0,0 +1,411 @@
+//go:build ignore
+// +build ignore
+
+// This program generates round.go, the portable implementation
+// of the block compression function, and blake2b_round.go,
+// the BLAKE2b compression function. Both are built on the same G function.
+//
+// Usage:
+//
+//	go run round_gen.go [-layout fused|pointer|value|rowcol] [-output round.go]
+//	go run round_gen.go -blake2b [-output blake2b_round.go]
+//
+// The layout selects how the permutation P is written:
+//
+//	fused    like rowcol, but computing a^b in the row pass and
+//	         XORing the result into z in the column pass (the default)
+//	pointer  P takes pointers to the 16 words it permutes
+//	value    P takes the 16 words as arguments and returns the results
+//	rowcol   separate permutations for the rows and for the columns of a block,
+//	         which load and store the words themselves
+//
+// Only the default layout is checked in; the others are for benchmarking.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+)
+
+var (
+	layout = flag.String("layout", "fused", "layout of the permutation: fused, pointer, value, or rowcol")
+	output = flag.String("output", "", "output file (default stdout)")
+	blake  = flag.Bool("blake2b", false, "generate the BLAKE2b compression function instead")
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("round_gen: ")
+	flag.Parse()
+
+	g := gen{tmp: "t"}
+	if *blake {
+		g.blake2b()
+	} else {
+		g.block()
+	}
+
+	src, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *output == "" {
+		_, err = os.Stdout.Write(src)
+	} else {
+		err = ioutil.WriteFile(*output, src, 0666)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+type gen struct {
+	buf bytes.Buffer
+	tmp string // name of the temporary variable in P
+}
+
+func (g *gen) p(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+	g.buf.WriteByte('\n')
+}
+
+// block writes the block compression function in the selected layout.
+func (g *gen) block() {
+	switch *layout {
+	case "fused":
+		g.fused()
+	case "pointer":
+		g.pointer()
+	case "value":
+		g.value()
+	case "rowcol":
+		g.rowcol()
+	default:
+		log.Fatalf("unknown layout %q", *layout)
+	}
+}
+
+// header writes the package clause and the start of the block function,
+// up to and including the computation of t = a ^ b.
+func (g *gen) header() {
+	g.p("// Code generated by go run round_gen.go. DO NOT EDIT.")
+	g.p("")
+	g.p("package argon2")
+	g.p("")
+	g.p("func blockGeneric(z, t, a, b *[128]uint64) {")
+	g.p("\t// compute z = z ^ a^b ^ P(a^b)")
+	g.p("")
+	g.p("\t// t = a ^ b")
+	for i := 0; i < 128; i++ {
+		g.p("\tt[%d] = a[%d] ^ b[%d]", i, i, i)
+	}
+	g.p("")
+	g.p("\t// t = P(t)")
+}
+
+// footer writes the end of the block function.
+func (g *gen) footer() {
+	g.p("")
+	g.p("\t// z = z ^ a^b ^ t")
+	for i := 0; i < 128; i++ {
+		g.p("\tz[%d] ^= a[%d] ^ b[%d] ^ t[%d]", i, i, i, i)
+	}
+	g.p("}")
+}
+
+// rows and columns return the indexes of the words of t
+// that P is applied to, first to each row and then to each column.
+func rows() [][]int {
+	var ps [][]int
+	for b := 0; b < 128; b += 16 {
+		var idx []int
+		for i := b; i < b+16; i++ {
+			idx = append(idx, i)
+		}
+		ps = append(ps, idx)
+	}
+	return ps
+}
+
+func columns() [][]int {
+	var ps [][]int
+	for b := 0; b < 16; b += 2 {
+		var idx []int
+		for i := b; i < 128; i += 16 {
+			idx = append(idx, i, i+1)
+		}
+		ps = append(ps, idx)
+	}
+	return ps
+}
+
+// list formats each index with the format and joins them with commas.
+func list(format string, idx []int) string {
+	s := make([]string, len(idx))
+	for i, x := range idx {
+		s[i] = fmt.Sprintf(format, x)
+	}
+	return strings.Join(s, ", ")
+}
+
+func seq(n int) []int {
+	idx := make([]int, n)
+	for i := range idx {
+		idx[i] = i
+	}
+	return idx
+}
+
+// quads are the arguments of the G function in each round,
+// first on the columns and then on the diagonals
+// of the 4x4 matrix of the variables v0 to v15.
+var quads = [8][4]string{
+	{"v0", "v4", "v8", "v12"},
+	{"v1", "v5", "v9", "v13"},
+	{"v2", "v6", "v10", "v14"},
+	{"v3", "v7", "v11", "v15"},
+	{"v0", "v5", "v10", "v15"},
+	{"v1", "v6", "v11", "v12"},
+	{"v2", "v7", "v8", "v13"},
+	{"v3", "v4", "v9", "v14"},
+}
+
+// rounds writes the body of P, which operates on the variables v0 to v15.
+func (g *gen) rounds() {
+	for _, q := range quads {
+		g.G(q[0], q[1], q[2], q[3])
+	}
+}
+
+// G writes the BlaMka G function used by Argon2 on a, b, c, and d.
+func (g *gen) G(a, b, c, d string) {
+	g.mix(a, b, c, d, "", "")
+}
+
+// mix writes the BLAKE2b G function on a, b, c, and d,
+// mixing in the message words x and y.
+// If x and y are empty, it writes the BlaMka G function instead,
+// in which each addition a + b is replaced by a + b + 2*lo(a)*lo(b).
+func (g *gen) mix(a, b, c, d, x, y string) {
+	blamka := x == ""
+	add := func(a, b, m string) {
+		switch {
+		case blamka:
+			g.p("\t%s = uint64(uint32(%s)) * uint64(uint32(%s))", g.tmp, a, b)
+			g.p("\t%s = %s + %s + %s*2", a, a, b, g.tmp)
+		case m != "":
+			g.p("\t%s = %s + %s + %s", a, a, b, m)
+		default:
+			g.p("\t%s = %s + %s", a, a, b)
+		}
+	}
+	xorRotate := func(a, b string, n int) {
+		g.p("\t%s = %s ^ %s", a, a, b)
+		g.p("\t%s = %s>>%d | %s<<%d", a, a, n, a, 64-n)
+	}
+	add(a, b, x)
+	xorRotate(d, a, 32)
+	add(c, d, "")
+	xorRotate(b, c, 24)
+	add(a, b, y)
+	xorRotate(d, a, 16)
+	add(c, d, "")
+	xorRotate(b, c, 63)
+}
+
+func (g *gen) pointer() {
+	g.header()
+	for _, idx := range rows() {
+		g.p("\t_P(%s)", list("&t[%d]", idx))
+	}
+	for _, idx := range columns() {
+		g.p("\t_P(%s)", list("&t[%d]", idx))
+	}
+	g.footer()
+
+	g.p("")
+	g.p("func _P(%s *uint64) {", list("p%d", seq(16)))
+	for i := 0; i < 16; i++ {
+		g.p("\tvar v%d = *p%d", i, i)
+	}
+	g.p("\tvar %s uint64", g.tmp)
+	g.rounds()
+	for i := 0; i < 16; i++ {
+		g.p("\t*p%d = v%d", i, i)
+	}
+	g.p("}")
+}
+
+func (g *gen) value() {
+	g.header()
+	for _, idx := range append(rows(), columns()...) {
+		g.p("\t%s = _P(%s)", list("t[%d]", idx), list("t[%d]", idx))
+	}
+	g.footer()
+
+	g.p("")
+	g.p("func _P(%s uint64) (%s) {", list("v%d", seq(16)), strings.Repeat("uint64, ", 15)+"uint64")
+	g.p("\tvar %s uint64", g.tmp)
+	g.rounds()
+	g.p("\treturn %s", list("v%d", seq(16)))
+	g.p("}")
+}
+
+func (g *gen) rowcol() {
+	g.header()
+	g.p("\tfor i := 0; i < 8; i++ {")
+	g.p("\t\tpRow(t, i)")
+	g.p("\t}")
+	g.p("\tfor i := 0; i < 8; i++ {")
+	g.p("\t\tpColumn(t, i)")
+	g.p("\t}")
+	g.footer()
+
+	// Slicing t first lets the compiler drop the bounds checks
+	g.p("")
+	g.p("// pRow applies P to row i of b.")
+	g.p("func pRow(b *[128]uint64, i int) {")
+	g.p("\tr := b[16*i : 16*i+16]")
+	g.p("\t_ = r[15]")
+	for i, x := range rows()[0] {
+		g.p("\tv%d := r[%d]", i, x)
+	}
+	g.p("\tvar %s uint64", g.tmp)
+	g.rounds()
+	for i, x := range rows()[0] {
+		g.p("\tr[%d] = v%d", x, i)
+	}
+	g.p("}")
+
+	g.p("")
+	g.p("// pColumn applies P to column i of b.")
+	g.p("func pColumn(b *[128]uint64, i int) {")
+	g.p("\tc := b[2*i : 2*i+114]")
+	g.p("\t_ = c[113]")
+	for i, x := range columns()[0] {
+		g.p("\tv%d := c[%d]", i, x)
+	}
+	g.p("\tvar %s uint64", g.tmp)
+	g.rounds()
+	for i, x := range columns()[0] {
+		g.p("\tc[%d] = v%d", x, i)
+	}
+	g.p("}")
+}
+
+func (g *gen) fused() {
+	g.tmp = "m" // t is taken
+	g.p("// Code generated by go run round_gen.go. DO NOT EDIT.")
+	g.p("")
+	g.p("package argon2")
+	g.p("")
+	g.p("// blockGeneric computes z ^= a^b ^ P(a^b), using t as scratch space.")
+	g.p("//")
+	g.p("// R = a^b is computed and folded into z a row at a time as P is applied")
+	g.p("// to the rows, and the result of applying P to the columns is folded")
+	g.p("// into z a column at a time, so that each block is only read")
+	g.p("// and written once per pass.")
+	g.p("func blockGeneric(z, t, a, b *[128]uint64) {")
+	for i := 0; i < 8; i++ {
+		g.p("\tpRow(z, t, a, b, %d)", i)
+	}
+	for i := 0; i < 8; i++ {
+		g.p("\tpColumn(z, t, %d)", i)
+	}
+	g.p("}")
+
+	// Slicing the blocks first lets the compiler drop the bounds checks
+	g.p("")
+	g.p("// pRow computes row i of R = a^b, XORs it into z,")
+	g.p("// and stores P of the row in t.")
+	g.p("func pRow(z, t, a, b *[128]uint64, i int) {")
+	g.p("\tzr, tr := z[16*i:16*i+16], t[16*i:16*i+16]")
+	g.p("\tar, br := a[16*i:16*i+16], b[16*i:16*i+16]")
+	g.p("\t_, _, _, _ = zr[15], tr[15], ar[15], br[15]")
+	for i, x := range rows()[0] {
+		g.p("\tv%d := ar[%d] ^ br[%d]", i, x, x)
+	}
+	for i, x := range rows()[0] {
+		g.p("\tzr[%d] ^= v%d", x, i)
+	}
+	g.p("\tvar %s uint64", g.tmp)
+	g.rounds()
+	for i, x := range rows()[0] {
+		g.p("\ttr[%d] = v%d", x, i)
+	}
+	g.p("}")
+
+	g.p("")
+	g.p("// pColumn applies P to column i of t and XORs the result into z.")
+	g.p("func pColumn(z, t *[128]uint64, i int) {")
+	g.p("\tzc, tc := z[2*i:2*i+114], t[2*i:2*i+114]")
+	g.p("\t_, _ = zc[113], tc[113]")
+	for i, x := range columns()[0] {
+		g.p("\tv%d := tc[%d]", i, x)
+	}
+	g.p("\tvar %s uint64", g.tmp)
+	g.rounds()
+	for i, x := range columns()[0] {
+		g.p("\tzc[%d] ^= v%d", x, i)
+	}
+	g.p("}")
+}
+
+// sigma is the BLAKE2b message schedule.
+var sigma = [10][16]int{
+	{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+	{14, 10, 4, 8, 9, 15, 13, 6, 1, 12, 0, 2, 11, 7, 5, 3},
+	{11, 8, 12, 0, 5, 2, 15, 13, 10, 14, 3, 6, 7, 1, 9, 4},
+	{7, 9, 3, 1, 13, 12, 11, 14, 2, 6, 5, 10, 4, 0, 15, 8},
+	{9, 0, 5, 7, 2, 4, 10, 15, 14, 1, 11, 12, 6, 8, 3, 13},
+	{2, 12, 6, 10, 0, 11, 8, 3, 4, 13, 7, 5, 15, 14, 1, 9},
+	{12, 5, 1, 15, 14, 13, 4, 10, 0, 7, 6, 3, 9, 2, 8, 11},
+	{13, 11, 7, 14, 12, 1, 3, 9, 5, 0, 15, 4, 8, 6, 2, 10},
+	{6, 15, 14, 9, 11, 3, 0, 8, 12, 2, 13, 7, 1, 4, 10, 5},
+	{10, 2, 8, 4, 7, 6, 1, 5, 15, 11, 9, 14, 3, 12, 13, 0},
+}
+
+// blake2b writes the BLAKE2b compression function,
+// with the message schedule applied at generation time.
+func (g *gen) blake2b() {
+	g.p("// Code generated by go run round_gen.go. DO NOT EDIT.")
+	g.p("")
+	g.p("package argon2")
+	g.p("")
+	g.p("// blake2bCompress is the BLAKE2b compression function F.")
+	g.p("// It mixes the message block m into the chain value h,")
+	g.p("// given the byte counter t1:t0 and the finalization flag f.")
+	g.p("func blake2bCompress(h *[8]uint64, m *[16]uint64, t0, t1, f uint64) {")
+	for i := 0; i < 8; i++ {
+		g.p("\tv%d := h[%d]", i, i)
+	}
+	for i := 0; i < 4; i++ {
+		g.p("\tv%d := blake2bIV[%d]", 8+i, i)
+	}
+	g.p("\tv12 := blake2bIV[4] ^ t0")
+	g.p("\tv13 := blake2bIV[5] ^ t1")
+	g.p("\tv14 := blake2bIV[6] ^ f")
+	g.p("\tv15 := blake2bIV[7]")
+	for i := 0; i < 16; i++ {
+		g.p("\tm%d := m[%d]", i, i)
+	}
+	for r := 0; r < 12; r++ {
+		g.p("")
+		g.p("\t// Round %d", r+1)
+		s := sigma[r%10]
+		for i, q := range quads {
+			g.mix(q[0], q[1], q[2], q[3], fmt.Sprintf("m%d", s[2*i]), fmt.Sprintf("m%d", s[2*i+1]))
+		}
+	}
+	g.p("")
+	for i := 0; i < 8; i++ {
+		g.p("\th[%d] ^= v%d ^ v%d", i, i, i+8)
+	}
+	g.p("}")
+}
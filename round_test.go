@@ -0,0 +1,89 @@
+package argon2
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func goTool(t *testing.T) string {
+	if testing.Short() {
+		t.Skip("skipping in short mode")
+	}
+	path, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool not found")
+	}
+	return path
+}
+
+// Checks that round.go and blake2b_round.go are up to date with round_gen.go
+func TestRoundGenerated(t *testing.T) {
+	gotool := goTool(t)
+	for _, tt := range []struct {
+		file string
+		args []string
+	}{
+		{"round.go", nil},
+		{"blake2b_round.go", []string{"-blake2b"}},
+	} {
+		args := append([]string{"run", "round_gen.go"}, tt.args...)
+		out, err := exec.Command(gotool, args...).Output()
+		if err != nil {
+			t.Fatalf("go run round_gen.go: %v", err)
+		}
+		want, err := ioutil.ReadFile(tt.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(out, want) {
+			t.Errorf("%s does not match the output of round_gen.go; run go generate", tt.file)
+		}
+	}
+}
+
+// Runs the test vectors with each of the alternative layouts,
+// in a copy of the package
+func TestRoundLayouts(t *testing.T) {
+	gotool := goTool(t)
+	files, err := filepath.Glob("*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, layout := range []string{"pointer", "value", "rowcol"} {
+		t.Run(layout, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "argon2-"+layout)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			for _, name := range files {
+				if !strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, ".s") && !strings.HasPrefix(name, "go.") {
+					continue
+				}
+				data, err := ioutil.ReadFile(name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0666); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			gen := exec.Command(gotool, "run", "round_gen.go", "-layout", layout, "-output", "round.go")
+			gen.Dir = dir
+			if out, err := gen.CombinedOutput(); err != nil {
+				t.Fatalf("go run round_gen.go: %v\n%s", err, out)
+			}
+			test := exec.Command(gotool, "test", "-tags", "purego", "-run", "^TestArgon", ".")
+			test.Dir = dir
+			if out, err := test.CombinedOutput(); err != nil {
+				t.Fatalf("go test: %v\n%s", err, out)
+			}
+		})
+	}
+}
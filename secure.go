@@ -0,0 +1,32 @@
+package argon2
+
+import (
+	"errors"
+	"strconv"
+)
+
+// ErrMemoryLock matches any *MemoryLockError when used with errors.Is.
+var ErrMemoryLock = errors.New("argon: cannot lock memory")
+
+// A MemoryLockError is returned when Params.SecureMemory is set
+// and the memory for the computation cannot be locked.
+// This usually means that RLIMIT_MEMLOCK is too small
+// (see ulimit -l, or LimitMEMLOCK in systemd),
+// or that the platform is not supported.
+type MemoryLockError struct {
+	Size  int64 // the number of bytes to lock
+	Limit int64 // RLIMIT_MEMLOCK in bytes, or -1 if unknown
+	Err   error // the underlying error
+}
+
+func (e *MemoryLockError) Error() string {
+	s := "argon: cannot lock " + strconv.FormatInt(e.Size, 10) + " bytes of memory"
+	if e.Limit >= 0 {
+		s += " (RLIMIT_MEMLOCK is " + strconv.FormatInt(e.Limit, 10) + " bytes)"
+	}
+	return s + ": " + e.Err.Error()
+}
+
+func (e *MemoryLockError) Unwrap() error { return e.Err }
+
+func (e *MemoryLockError) Is(target error) bool { return target == ErrMemoryLock }
@@ -0,0 +1,82 @@
+package argon2
+
+import (
+	"errors"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// countingAllocator is a heap allocator which counts its allocations.
+type countingAllocator struct {
+	heapAllocator
+	n int
+}
+
+func (a *countingAllocator) alloc(n int) ([][128]uint64, error) {
+	a.n++
+	return a.heapAllocator.alloc(n)
+}
+
+// Checks that SecureMemory also applies to verifying the stored hash
+func TestSecureMemoryVerify(t *testing.T) {
+	a := new(countingAllocator)
+	defer func(saved allocator) { secureAllocator = saved }(secureAllocator)
+	secureAllocator = a
+
+	pw := []byte("password")
+	old := &Hash{Variant: Argon2i, Version: Version13, Memory: 16, Time: 1, Lanes: 1, Salt: repeat(5, 16)}
+	p := old.Params()
+	p.TagLength = 32
+	var err error
+	if old.Key, err = p.Key(pw, old.Salt); err != nil {
+		t.Fatal(err)
+	}
+	if a.n != 0 {
+		t.Fatalf("%d secure allocations without SecureMemory", a.n)
+	}
+
+	policy := rehashPolicy
+	policy.SecureMemory = true
+	s, err := VerifyAndRehash(old.String(), pw, policy)
+	if err != nil || s == "" {
+		t.Fatalf("VerifyAndRehash = %q, %v, want a replacement hash", s, err)
+	}
+	if a.n != 2 {
+		t.Errorf("VerifyAndRehash made %d secure allocations, want 2", a.n)
+	}
+
+	a.n = 0
+	if s, err := VerifyAndRehash(s, pw, policy); err != nil || s != "" {
+		t.Fatalf("VerifyAndRehash of the replacement = %q, %v", s, err)
+	}
+	if a.n != 1 {
+		t.Errorf("VerifyAndRehash without a rehash made %d secure allocations, want 1", a.n)
+	}
+
+	a.n = 0
+	if err := (&VerifyPolicy{SecureMemory: true}).Verify(s, pw); err != nil {
+		t.Fatal(err)
+	}
+	if a.n != 1 {
+		t.Errorf("VerifyPolicy.Verify made %d secure allocations, want 1", a.n)
+	}
+
+	a.n = 0
+	if err := Verify(s, pw); err != nil {
+		t.Fatal(err)
+	}
+	if a.n != 0 {
+		t.Errorf("Verify made %d secure allocations, want 0", a.n)
+	}
+}
+
+func TestMemoryLockError(t *testing.T) {
+	err := error(&MemoryLockError{Size: 1 << 20, Limit: 65536, Err: syscall.ENOMEM})
+	if !errors.Is(err, ErrMemoryLock) || !errors.Is(err, syscall.ENOMEM) {
+		t.Errorf("%v does not match both ErrMemoryLock and ENOMEM", err)
+	}
+	if s := err.Error(); !strings.Contains(s, "1048576 bytes") || !strings.Contains(s, "RLIMIT_MEMLOCK is 65536 bytes") {
+		t.Errorf("message %q does not give the size and limit", s)
+	}
+}
@@ -0,0 +1,157 @@
+package argon2
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"strconv"
+)
+
+// A Tracer receives events describing the steps of a key derivation.
+// It is meant for debugging, such as comparing this implementation
+// against another one.
+//
+// Events are delivered in order from a single goroutine;
+// lanes are not filled concurrently while tracing.
+// Slices and arrays referenced by an event are only valid
+// for the duration of the call to Trace.
+//
+// Warning: the events reveal secret, password-derived values.
+type Tracer interface {
+	Trace(e Event)
+}
+
+// An Event is one of InputHashEvent, ReferenceEvent, BlockEvent,
+// PassEvent, or FinalBlockEvent.
+type Event interface {
+	event()
+}
+
+// An InputHashEvent reports the hash H0 of the inputs and parameters,
+// which is used to initialize the matrix.
+type InputHashEvent struct {
+	Variant   Variant
+	Version   uint32
+	Lanes     uint32
+	Memory    uint32 // requested memory, in kibibytes
+	Time      uint32
+	TagLength uint32
+	Hash      []byte // 64 bytes
+}
+
+// A ReferenceEvent reports which block was chosen as the reference block
+// for computing the block at the given position.
+type ReferenceEvent struct {
+	Pass, Slice, Lane uint32
+	Index             uint32 // index of the new block within its lane
+	Rand              uint64 // the pseudo-random value which chose the reference
+	RefLane           uint32
+	RefIndex          uint32 // index of the reference block within its lane
+}
+
+// A BlockEvent reports that a block has been computed.
+type BlockEvent struct {
+	Pass, Slice, Lane uint32
+	Index             uint32 // index of the block within its lane
+	Block             *[128]uint64
+}
+
+// A PassEvent reports that a pass over the matrix has been completed.
+type PassEvent struct {
+	Pass   uint32
+	Blocks [][128]uint64 // the whole matrix, lane by lane
+}
+
+// A FinalBlockEvent reports the XOR of the last block of each lane,
+// which is hashed to produce the tag.
+type FinalBlockEvent struct {
+	Block *[1024]byte
+}
+
+func (InputHashEvent) event()  {}
+func (ReferenceEvent) event()  {}
+func (BlockEvent) event()      {}
+func (PassEvent) event()       {}
+func (FinalBlockEvent) event() {}
+
+// NewJSONTracer returns a Tracer that writes each event to w
+// as a single line of JSON.
+//
+// Blocks are written in hex, in little-endian byte order.
+// PassEvents are written without their blocks,
+// since each block has already been written by a BlockEvent.
+// Errors writing to w are ignored.
+func NewJSONTracer(w io.Writer) Tracer {
+	return &jsonTracer{enc: json.NewEncoder(w)}
+}
+
+type jsonTracer struct {
+	enc *json.Encoder
+}
+
+type jsonEvent struct {
+	Event     string `json:"event"`
+	Variant   string `json:"variant,omitempty"`
+	Version   uint32 `json:"version,omitempty"`
+	Lanes     uint32 `json:"lanes,omitempty"`
+	Memory    uint32 `json:"memory,omitempty"`
+	Time      uint32 `json:"time,omitempty"`
+	TagLength uint32 `json:"tag_length,omitempty"`
+
+	Pass     *uint32 `json:"pass,omitempty"`
+	Slice    *uint32 `json:"slice,omitempty"`
+	Lane     *uint32 `json:"lane,omitempty"`
+	Index    *uint32 `json:"index,omitempty"`
+	Rand     string  `json:"rand,omitempty"`
+	RefLane  *uint32 `json:"ref_lane,omitempty"`
+	RefIndex *uint32 `json:"ref_index,omitempty"`
+
+	Hash  string `json:"hash,omitempty"`
+	Block string `json:"block,omitempty"`
+}
+
+func (t *jsonTracer) Trace(e Event) {
+	var j jsonEvent
+	switch e := e.(type) {
+	case InputHashEvent:
+		j = jsonEvent{
+			Event:     "input",
+			Variant:   e.Variant.String(),
+			Version:   e.Version,
+			Lanes:     e.Lanes,
+			Memory:    e.Memory,
+			Time:      e.Time,
+			TagLength: e.TagLength,
+			Hash:      hex.EncodeToString(e.Hash[:]),
+		}
+	case ReferenceEvent:
+		j = jsonEvent{
+			Event:    "reference",
+			Pass:     &e.Pass,
+			Slice:    &e.Slice,
+			Lane:     &e.Lane,
+			Index:    &e.Index,
+			Rand:     strconv.FormatUint(e.Rand, 16),
+			RefLane:  &e.RefLane,
+			RefIndex: &e.RefIndex,
+		}
+	case BlockEvent:
+		var buf [1024]byte
+		putBlock(buf[:], e.Block)
+		j = jsonEvent{
+			Event: "block",
+			Pass:  &e.Pass,
+			Slice: &e.Slice,
+			Lane:  &e.Lane,
+			Index: &e.Index,
+			Block: hex.EncodeToString(buf[:]),
+		}
+	case PassEvent:
+		j = jsonEvent{Event: "pass", Pass: &e.Pass}
+	case FinalBlockEvent:
+		j = jsonEvent{Event: "final", Block: hex.EncodeToString(e.Block[:])}
+	default:
+		return
+	}
+	t.enc.Encode(&j)
+}
@@ -0,0 +1,127 @@
+package argon2
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+// logWriter writes to the test log.
+type logWriter struct{ t *testing.T }
+
+func (w logWriter) Write(p []byte) (int, error) {
+	w.t.Log(string(bytes.TrimRight(p, "\n")))
+	return len(p), nil
+}
+
+type recorder struct {
+	events []Event
+	last   map[uint32][128]uint64 // last block computed in each lane
+	final  [1024]byte
+}
+
+func (r *recorder) Trace(e Event) {
+	switch e := e.(type) {
+	case BlockEvent:
+		r.last[e.Lane] = *e.Block
+	case FinalBlockEvent:
+		r.final = *e.Block
+	}
+	r.events = append(r.events, e)
+}
+
+func TestTracer(t *testing.T) {
+	p := Params{Variant: Argon2id, Time: 2, Memory: 16, Lanes: 2, Threads: 4, TagLength: 16}
+	want, err := p.Key(zeros[:], ones[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := &recorder{last: make(map[uint32][128]uint64)}
+	p.Tracer = r
+	got, err := p.Key(zeros[:], ones[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("tracing changed the key: got %x, want %x", got, want)
+	}
+
+	// 1 input hash, 2 initial blocks per lane,
+	// a reference and a block for each of the 16*2-4 remaining blocks,
+	// 2 passes, and the final block
+	if n := len(r.events); n != 1+4+2*28+2+1 {
+		t.Errorf("got %d events, want %d", n, 1+4+2*28+2+1)
+	}
+	if e, ok := r.events[0].(InputHashEvent); !ok || e.Variant != Argon2id || e.Version != Version13 || e.Memory != 16 || e.TagLength != 16 {
+		t.Errorf("first event: got %+v", r.events[0])
+	}
+	if _, ok := r.events[len(r.events)-1].(FinalBlockEvent); !ok {
+		t.Errorf("last event: got %T, want FinalBlockEvent", r.events[len(r.events)-1])
+	}
+
+	var pass uint32
+	for i, e := range r.events {
+		switch e := e.(type) {
+		case ReferenceEvent:
+			b, ok := r.events[i+1].(BlockEvent)
+			if !ok || b.Pass != e.Pass || b.Lane != e.Lane || b.Index != e.Index {
+				t.Errorf("event %d: reference %+v not followed by its block", i, e)
+			}
+			if e.Pass != pass {
+				t.Errorf("event %d: got pass %d, want %d", i, e.Pass, pass)
+			}
+		case PassEvent:
+			if e.Pass != pass || len(e.Blocks) != 16 {
+				t.Errorf("event %d: got pass %d with %d blocks, want pass %d with 16", i, e.Pass, len(e.Blocks), pass)
+			}
+			pass++
+		}
+	}
+
+	var x [128]uint64
+	for _, b := range r.last {
+		for i := range x {
+			x[i] ^= b[i]
+		}
+	}
+	var buf [1024]byte
+	putBlock(buf[:], &x)
+	if buf != r.final {
+		t.Errorf("final block is not the XOR of the last blocks")
+	}
+}
+
+func TestJSONTracer(t *testing.T) {
+	var buf bytes.Buffer
+	var out [8]byte
+	argon2(context.Background(), out[:], zeros[:], ones[:], nil, nil, 1, 8, 1, Argon2i, Version13, 1, nil, NewJSONTracer(&buf), nil)
+
+	var events []map[string]interface{}
+	sc := bufio.NewScanner(&buf)
+	sc.Buffer(nil, 1<<16)
+	for sc.Scan() {
+		var e map[string]interface{}
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			t.Fatalf("%v: %s", err, sc.Bytes())
+		}
+		events = append(events, e)
+	}
+	if len(events) != 1+2+2*6+1+1 {
+		t.Fatalf("got %d events, want %d", len(events), 1+2+2*6+1+1)
+	}
+	if e := events[0]; e["event"] != "input" || e["variant"] != "argon2i" || len(e["hash"].(string)) != 128 {
+		t.Errorf("first event: got %v", e)
+	}
+	if e := events[3]; e["event"] != "reference" || e["pass"] != 0.0 || e["index"] != 2.0 {
+		t.Errorf("fourth event: got %v", e)
+	}
+	if e := events[4]; e["event"] != "block" || e["index"] != 2.0 || len(e["block"].(string)) != 2048 {
+		t.Errorf("fifth event: got %v", e)
+	}
+	if e := events[len(events)-1]; e["event"] != "final" || len(e["block"].(string)) != 2048 {
+		t.Errorf("last event: got %v", e)
+	}
+}
@@ -0,0 +1,125 @@
+package argon2
+
+import "errors"
+
+// ErrExceedsPolicy matches any *PolicyError when used with errors.Is.
+var ErrExceedsPolicy = errors.New("argon: hash exceeds verification policy")
+
+// A PolicyError is returned by the methods of VerifyPolicy
+// when a well-formed hash exceeds the policy.
+// It is distinct from a *HashError, and does not match ErrInvalidHash.
+type PolicyError struct {
+	Field string // the field which exceeds the policy: "m", "t", "p", or "hash"
+}
+
+func (e *PolicyError) Error() string {
+	return "argon: hash exceeds verification policy: " + e.Field
+}
+
+func (e *PolicyError) Is(target error) bool { return target == ErrExceedsPolicy }
+
+// A VerifyPolicy caps the cost of verifying encoded hashes
+// which may have been tampered with, such as hashes read from a database
+// or supplied by a user.
+//
+// The parameters of an encoded hash control how much memory and time
+// verifying it takes, up to terabytes of memory and billions of passes.
+// The methods of VerifyPolicy reject a hash which exceeds the policy
+// before doing any work, with a *PolicyError.
+//
+// A zero field means no limit.
+type VerifyPolicy struct {
+	MaxMemory    uint32 // m, in kibibytes
+	MaxTime      uint32 // t, the number of passes
+	MaxLanes     uint32 // p, the degree of parallelism
+	MaxTagLength uint32 // the length of the hash, in bytes
+
+	// Limiter, if not nil, bounds the memory used to verify passwords
+	// together with all other computations sharing the Limiter.
+	// VerifyAndRehash also uses it for the replacement hash
+	// if the policy passed to it has no Limiter of its own.
+	Limiter *Limiter
+
+	// SecureMemory, if true, verifies passwords
+	// as if Params.SecureMemory were set,
+	// including in VerifyAndRehash, whose replacement hash gets it too.
+	SecureMemory bool
+}
+
+// Check returns a *PolicyError if the parameters of h exceed the policy,
+// or nil otherwise. The memory compared against MaxMemory
+// is the amount actually used, as reported by Params.EffectiveMemory.
+func (vp *VerifyPolicy) Check(h *Hash) error {
+	exceeds := func(v int64, max uint32) bool {
+		return max != 0 && v > int64(max)
+	}
+	switch {
+	case exceeds(int64(h.Lanes), vp.MaxLanes):
+		return &PolicyError{"p"}
+	case h.Lanes > 0 && exceeds(int64(numBlocks(h.Memory, h.Lanes)), vp.MaxMemory):
+		return &PolicyError{"m"}
+	case exceeds(int64(h.Time), vp.MaxTime):
+		return &PolicyError{"t"}
+	case exceeds(int64(len(h.Key)), vp.MaxTagLength):
+		return &PolicyError{"hash"}
+	}
+	return nil
+}
+
+// ParseHash is like the package-level ParseHash,
+// but also returns an error if the hash exceeds the policy.
+func (vp *VerifyPolicy) ParseHash(s string) (*Hash, error) {
+	h, err := ParseHash(s)
+	if err != nil {
+		return nil, err
+	}
+	if err := vp.Check(h); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// Verify is like the package-level Verify,
+// but returns an error without hashing the password
+// if the hash exceeds the policy.
+func (vp *VerifyPolicy) Verify(encoded string, password []byte) error {
+	return vp.VerifyWithSecret(encoded, password, nil)
+}
+
+// VerifyWithSecret is like Verify, for hashes computed with a secret key.
+func (vp *VerifyPolicy) VerifyWithSecret(encoded string, password, secret []byte) error {
+	h, err := vp.ParseHash(encoded)
+	if err != nil {
+		return err
+	}
+	return h.verify(password, secret, vp.exec())
+}
+
+// VerifyAndRehash is like the package-level VerifyAndRehash,
+// but returns an error without hashing the password
+// if the stored hash exceeds the policy.
+func (vp *VerifyPolicy) VerifyAndRehash(encoded string, password []byte, policy Params) (string, error) {
+	return vp.VerifyAndRehashWithSecret(encoded, password, nil, policy)
+}
+
+// VerifyAndRehashWithSecret is like VerifyAndRehash,
+// for hashes computed with a secret key.
+func (vp *VerifyPolicy) VerifyAndRehashWithSecret(encoded string, password, secret []byte, policy Params) (string, error) {
+	h, err := vp.ParseHash(encoded)
+	if err != nil {
+		return "", err
+	}
+	if policy.Limiter == nil {
+		policy.Limiter = vp.Limiter
+	}
+	if vp.SecureMemory {
+		policy.SecureMemory = true
+	}
+	return h.verifyAndRehash(password, secret, &policy)
+}
+
+// exec returns the parameters controlling how hashes are verified,
+// apart from those recorded in the hash.
+func (vp *VerifyPolicy) exec() *Params {
+	return &Params{Limiter: vp.Limiter, SecureMemory: vp.SecureMemory}
+}
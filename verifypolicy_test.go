@@ -0,0 +1,124 @@
+package argon2
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestVerifyPolicy(t *testing.T) {
+	vp := &VerifyPolicy{MaxMemory: 64, MaxTime: 3, MaxLanes: 4, MaxTagLength: 32}
+	pw := []byte("password")
+	const ok = "$argon2id$v=19$m=64,t=3,p=4$c29tZXNhbHQ$T4fNMJtyzPmC46C+DDai6FF5I8o+6vKMbvxnbQkvttU"
+	if err := vp.Verify(ok, pw); err != nil {
+		t.Errorf("Verify within policy: %v", err)
+	}
+	if err := vp.Verify(ok, []byte("hunter2")); err != ErrMismatch {
+		t.Errorf("Verify wrong password: got %v, want ErrMismatch", err)
+	}
+
+	const salt = "c29tZXNhbHQ"
+	const key = "T4fNMJtyzPmC46C+DDai6FF5I8o+6vKMbvxnbQkvttU"
+	tests := []struct {
+		hash  string
+		field string
+	}{
+		{"$argon2id$v=19$m=4294967295,t=3,p=4$" + salt + "$" + key, "m"},
+		{"$argon2id$v=19$m=64,t=4294967295,p=4$" + salt + "$" + key, "t"},
+		{"$argon2id$v=19$m=64,t=3,p=5$" + salt + "$" + key, "p"},
+		{"$argon2id$v=19$m=64,t=3,p=4$" + salt + "$" + key + "AAAA", "hash"},
+	}
+	for _, tt := range tests {
+		// These would take forever or run out of memory if attempted
+		err := vp.Verify(tt.hash, pw)
+		var pe *PolicyError
+		if !errors.As(err, &pe) || pe.Field != tt.field || !errors.Is(err, ErrExceedsPolicy) {
+			t.Errorf("Verify(%s): got %v, want %s: %v", tt.hash, err, tt.field, ErrExceedsPolicy)
+		}
+		// A hash over the policy is not malformed
+		if errors.Is(err, ErrInvalidHash) {
+			t.Errorf("Verify(%s): got %v, which matches ErrInvalidHash", tt.hash, err)
+		}
+		if _, err := vp.ParseHash(tt.hash); !errors.Is(err, ErrExceedsPolicy) {
+			t.Errorf("ParseHash(%s): got %v, want ErrExceedsPolicy", tt.hash, err)
+		}
+		if _, err := vp.VerifyAndRehash(tt.hash, pw, rehashPolicy); !errors.Is(err, ErrExceedsPolicy) {
+			t.Errorf("VerifyAndRehash(%s): got %v, want ErrExceedsPolicy", tt.hash, err)
+		}
+		if _, err := ParseHash(tt.hash); err != nil {
+			t.Errorf("ParseHash(%s) without a policy: %v", tt.hash, err)
+		}
+	}
+
+	// Zero fields are unlimited
+	if err := (&VerifyPolicy{}).Check(&Hash{Memory: maxMemory, Time: maxIter, Lanes: maxPar}); err != nil {
+		t.Errorf("Check with zero policy: %v", err)
+	}
+
+	// The memory is compared after rounding, which can increase it
+	// for a hash which did not come from ParseHash
+	if err := (&VerifyPolicy{MaxMemory: 64}).Check(&Hash{Memory: 8, Time: 1, Lanes: 16}); !errors.Is(err, ErrExceedsPolicy) {
+		t.Errorf("Check with 16 lanes of 8 blocks: got %v, want ErrExceedsPolicy", err)
+	}
+	if err := (&VerifyPolicy{MaxMemory: 64}).Check(&Hash{Memory: 66, Time: 1, Lanes: 4}); err != nil {
+		t.Errorf("Check with 64 blocks in use: %v", err)
+	}
+
+	// Errors parsing the hash come first
+	if err := vp.Verify("$argon2id$v=19$m=4294967295,t=3,p=4$"+salt, pw); !errors.Is(err, ErrMalformed) {
+		t.Errorf("Verify malformed hash: got %v, want ErrMalformed", err)
+	}
+}
+
+// Checks that verification waits for the policy's Limiter
+func TestVerifyPolicyLimiter(t *testing.T) {
+	const hash = "$argon2id$v=19$m=64,t=3,p=4$c29tZXNhbHQ$T4fNMJtyzPmC46C+DDai6FF5I8o+6vKMbvxnbQkvttU"
+	l := NewLimiter(100)
+	vp := &VerifyPolicy{Limiter: l}
+	if !l.TryAcquire(50) {
+		t.Fatal("TryAcquire(50) = false")
+	}
+
+	done := make(chan error, 2)
+	go func() { done <- vp.Verify(hash, []byte("password")) }()
+	waitQueued(t, l, 1)
+	go func() {
+		_, err := vp.VerifyAndRehash(hash, []byte("password"), rehashPolicy)
+		done <- err
+	}()
+	waitQueued(t, l, 2)
+	select {
+	case err := <-done:
+		t.Fatalf("verified with the Limiter full: %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	l.Release(50)
+	for i := 0; i < 2; i++ {
+		if err := <-done; err != nil {
+			t.Error(err)
+		}
+	}
+	if got := l.InUse(); got != 0 {
+		t.Errorf("InUse() = %d after verifying, want 0", got)
+	}
+
+	// A request larger than the budget fails instead of waiting
+	vp.Limiter = NewLimiter(32)
+	if err := vp.Verify(hash, []byte("password")); err != ErrMemoryBudget {
+		t.Errorf("Verify over budget: got %v, want ErrMemoryBudget", err)
+	}
+	policy := rehashPolicy
+	policy.Limiter = vp.Limiter
+	if _, err := VerifyAndRehash(hash, []byte("password"), policy); err != ErrMemoryBudget {
+		t.Errorf("VerifyAndRehash over budget: got %v, want ErrMemoryBudget", err)
+	}
+}
+
+func ExampleVerifyPolicy() {
+	vp := &VerifyPolicy{MaxMemory: 1 << 20, MaxTime: 10, MaxLanes: 16, MaxTagLength: 64}
+	tampered := "$argon2id$v=19$m=4294967295,t=4294967295,p=4$c29tZXNhbHQ$T4fNMJtyzPmC46C+DDai6FF5I8o+6vKMbvxnbQkvttU"
+	fmt.Println(vp.Verify(tampered, []byte("password")))
+	// Output: argon: hash exceeds verification policy: m
+}